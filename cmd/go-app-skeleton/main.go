@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	gormLogger "gorm.io/gorm/logger"
 
 	"go.uber.org/fx"
@@ -18,12 +19,32 @@ import (
 	"github.com/imperiuse/go-app-skeleton/internal/config"
 	"github.com/imperiuse/go-app-skeleton/internal/database"
 	"github.com/imperiuse/go-app-skeleton/internal/database/migration"
+	"github.com/imperiuse/go-app-skeleton/internal/database/pagination"
 	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tenancy"
 	"github.com/imperiuse/go-app-skeleton/internal/logger"
 	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
 	"github.com/imperiuse/go-app-skeleton/internal/servers/api"
+	apikeyController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apikey"
+	problemsController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/problems"
+	rbacController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/rbac"
+	searchController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/search"
+	sessionController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/session"
+	tenantController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/tenant"
+	usageController "github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/usage"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
 	"github.com/imperiuse/go-app-skeleton/internal/servers/metrics"
 	"github.com/imperiuse/go-app-skeleton/internal/servers/pprof"
+	"github.com/imperiuse/go-app-skeleton/internal/services/apikey"
+	"github.com/imperiuse/go-app-skeleton/internal/services/cache"
+	"github.com/imperiuse/go-app-skeleton/internal/services/concurrency"
+	"github.com/imperiuse/go-app-skeleton/internal/services/idempotency"
+	"github.com/imperiuse/go-app-skeleton/internal/services/metering"
+	"github.com/imperiuse/go-app-skeleton/internal/services/ratelimit"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+	"github.com/imperiuse/go-app-skeleton/internal/services/search"
+	"github.com/imperiuse/go-app-skeleton/internal/services/session"
+	"github.com/imperiuse/go-app-skeleton/internal/services/tenant"
 
 	// Automatically set GOMAXPROCS to match Linux container CPU quota.
 	_ "go.uber.org/automaxprocs"
@@ -35,6 +56,8 @@ import (
 const (
 	appStartTimeout = 60 * time.Second
 	appStopTimeout  = 60 * time.Second
+
+	searchIndexesTimeout = 10 * time.Second
 )
 
 // Program flags.
@@ -92,13 +115,21 @@ func (a *application) run() {
 				return config.New(a.configPath)
 			},
 			func(cfg *config.Config) (*logger.Logger, error) {
-				return logger.New(logger.Config{
+				log, err := logger.New(logger.Config{
 					Level:    cfg.GetString("logger.level"),
 					Encoding: cfg.GetString("logger.encoding"),
 					Color:    cfg.GetBoolean("logger.color"),
 					Outputs:  cfg.GetStringSlice("logger.outputs"),
 					Tags:     cfg.GetStringSlice("logger.tags"),
 				}, cfg.GetCurrentEnvironment(), config.AppName, a.version)
+				if err != nil {
+					return nil, err
+				}
+
+				// logger.FromContext(ctx) returns it for contexts without request-scoped logger.
+				logger.SetDefault(log)
+
+				return log, nil
 			},
 			func(cfg *config.Config, log *logger.Logger) gormLogger.Interface {
 				var lvl = gormLogger.Warn
@@ -127,18 +158,198 @@ func (a *application) run() {
 					Address: cfg.GetString("servers.metrics.addr"),
 				}, log)
 			},
-			func(cfg *config.Config, e *api.Engine, log *logger.Logger) *api.Server {
+			func(cfg *config.Config, db *database.DB, log *logger.Logger) *session.Service {
+				return session.New(session.Config{
+					TTL:           cfg.GetDuration("sessions.ttl"),
+					PurgeInterval: cfg.GetDuration("sessions.purge_interval"),
+				}, db, log)
+			},
+			func(cfg *config.Config, db *database.DB, log *logger.Logger) *idempotency.Service {
+				return idempotency.New(idempotency.Config{
+					TTL:           cfg.GetDuration("idempotency.ttl"),
+					LockTimeout:   cfg.GetDuration("idempotency.lock_timeout"),
+					PurgeInterval: cfg.GetDuration("idempotency.purge_interval"),
+				}, db, log)
+			},
+			func(cfg *config.Config, db *database.DB) *rbac.Service {
+				return rbac.New(rbac.Config{
+					CacheTTL: cfg.GetDuration("rbac.cache_ttl"),
+				}, db)
+			},
+			// response cache is optional: nil store if it's disabled.
+			func(cfg *config.Config) cache.Store {
+				if !cfg.GetBoolOrDefaultValue("servers.api.response_cache.enabled", false) {
+					return nil
+				}
+
+				return cache.NewLRU(cache.LRUConfig{
+					MaxEntries: cfg.GetIntOrDefaultValue("servers.api.response_cache.max_entries", 0),
+					MaxBytes:   cfg.GetIntOrDefaultValue("servers.api.response_cache.max_bytes", 0),
+				})
+			},
+			func(cfg *config.Config, db *database.DB, responses cache.Store) *tenant.Service {
+				return tenant.New(tenant.Config{
+					CacheTTL: cfg.GetDuration("tenants.cache_ttl"),
+				}, db, responses)
+			},
+			// metering is optional: nil service if it's disabled.
+			func(cfg *config.Config, db *database.DB, tenants *tenant.Service, log *logger.Logger) *metering.Service {
+				if !cfg.GetBoolOrDefaultValue("metering.enabled", false) {
+					return nil
+				}
+
+				return metering.New(meteringConfig(cfg), db, tenants, log)
+			},
+			apikey.New,
+			func(cfg *config.Config, log *logger.Logger) (*pagination.Codec, error) {
+				secret := cfg.GetStringOrDefaultValue("pagination.cursor_secret", "")
+				if secret == "" {
+					log.Warn("pagination.cursor_secret is empty, page cursors are valid only for this replica")
+				}
+
+				return pagination.NewCodec([]byte(secret))
+			},
+			// search is optional: nil client and indexer if it's disabled.
+			func(cfg *config.Config, log *logger.Logger) (*search.Client, *search.Indexer, error) {
+				if !cfg.GetBoolOrDefaultValue("search.enabled", false) {
+					return nil, nil, nil
+				}
+
+				searchCfg := search.Config{
+					Addresses:   cfg.GetStringSlice("search.addresses"),
+					Username:    cfg.GetStringOrDefaultValue("search.username", ""),
+					Password:    cfg.GetStringOrDefaultValue("search.password", ""),
+					IndexPrefix: cfg.GetStringOrDefaultValue("search.index_prefix", ""),
+					Timeout:     cfg.GetDuration("search.timeout"),
+				}
+				if cfg.IsDevelopmentEnv() {
+					searchCfg.Logger = logger.NewLoggerForEs(log)
+				}
+
+				client, err := search.NewClient(searchCfg)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				return client, search.NewIndexer(client, search.IndexerConfig{
+					BulkSize:      cfg.GetIntOrDefaultValue("search.bulk_size", 0),
+					FlushInterval: cfg.GetDuration("search.flush_interval"),
+					QueueSize:     cfg.GetIntOrDefaultValue("search.queue_size", 0),
+				}, log), nil
+			},
+			func(cfg *config.Config, db *database.DB, log *logger.Logger) ratelimit.Limiter {
+				if cfg.GetStringOrDefaultValue("servers.api.rate_limit.backend", "memory") == "postgres" {
+					return ratelimit.NewPostgres(db, log)
+				}
+
+				return ratelimit.NewMemory()
+			},
+			func(cfg *config.Config) mw.SessionCookie {
+				return mw.SessionCookie{
+					Name:   cfg.GetStringOrDefaultValue("sessions.cookie_name", "session"),
+					Domain: cfg.GetStringOrDefaultValue("sessions.cookie_domain", ""),
+					Secure: cfg.GetBoolOrDefaultValue("sessions.secure_cookie", true),
+				}
+			},
+			func(
+				cfg *config.Config,
+				sessions *session.Service,
+				cookie mw.SessionCookie,
+				rbacService *rbac.Service,
+				apiKeys *apikey.Service,
+				limiter ratelimit.Limiter,
+				cursors *pagination.Codec,
+				searchClient *search.Client,
+				tenants *tenant.Service,
+				meter *metering.Service,
+				idempotencyKeys *idempotency.Service,
+				responses cache.Store,
+			) api.Deps {
+				var engineMiddlewares []gin.HandlerFunc
+				if cfg.GetBoolOrDefaultValue("servers.api.load_shed.enabled", false) {
+					engineMiddlewares = append(engineMiddlewares, loadShedMiddleware(cfg))
+				}
+				if cfg.GetBoolOrDefaultValue("servers.api.compression.enabled", false) {
+					engineMiddlewares = append(engineMiddlewares,
+						mw.DecompressMiddleware(mw.DecompressConfig{
+							MaxBodySize: int64(cfg.GetIntOrDefaultValue("servers.api.compression.max_request_body", 0)),
+						}),
+						mw.CompressMiddleware(mw.CompressConfig{
+							MinSize:      cfg.GetIntOrDefaultValue("servers.api.compression.min_size", 0),
+							ContentTypes: cfg.GetStringSlice("servers.api.compression.content_types"),
+						}),
+					)
+				}
+
+				var middlewares []gin.HandlerFunc
+				if cfg.GetBoolOrDefaultValue("servers.api.rate_limit.enabled", false) {
+					middlewares = append(middlewares, mw.RateLimitMiddleware(limiter, rateLimitConfig(cfg)))
+				}
+
+				middlewares = append(middlewares, mw.TenantStatusMiddleware(tenants))
+				if meter != nil {
+					middlewares = append(middlewares, mw.QuotaMiddleware(meter, metering.Requests))
+				}
+				if cfg.GetBoolOrDefaultValue("servers.api.etag.enabled", false) {
+					middlewares = append(middlewares, mw.ETagMiddleware(mw.ETagConfig{
+						MaxBodySize: cfg.GetIntOrDefaultValue("servers.api.etag.max_body_size", 0),
+					}))
+				}
+				if cfg.GetBoolOrDefaultValue("idempotency.enabled", false) {
+					middlewares = append(middlewares, mw.IdempotencyMiddleware(idempotencyKeys, mw.IdempotencyConfig{
+						MaxBodySize: int64(cfg.GetIntOrDefaultValue("idempotency.max_body_size", 0)),
+					}))
+				}
+
+				controllers := []api.Controller{
+					sessionController.New(sessions, cookie),
+					rbacController.New(rbacService),
+					apikeyController.New(apiKeys, rbacService, tenants, cursors),
+					tenantController.New(tenants, rbacService),
+					problemsController.New(),
+				}
+				if searchClient != nil {
+					controllers = append(controllers, searchController.New(searchClient, rbacService,
+						cfg.GetStringOrDefaultValue("search.default_index", tables.APIKeyIndex)))
+				}
+				if meter != nil {
+					var usageCache gin.HandlerFunc
+					if responses != nil {
+						usageCache = mw.CacheMiddleware(responses, mw.CacheRule{
+							TTL: cfg.GetDuration("servers.api.response_cache.usage_ttl"),
+						})
+					}
+
+					controllers = append(controllers, usageController.New(meter, rbacService, usageCache))
+				}
+
+				authMiddlewares := []gin.HandlerFunc{mw.AuthMiddleware(sessions, cookie, apiKeys)}
+				if cfg.GetBoolOrDefaultValue("servers.api.rate_limit.enabled", false) {
+					// credentials are checked by auth, so brute force of them is limited by ip before it.
+					authMiddlewares = append([]gin.HandlerFunc{
+						mw.RateLimitMiddleware(limiter, preAuthRateLimitConfig(cfg)),
+					}, authMiddlewares...)
+				}
+
+				return api.Deps{
+					EngineMiddlewares: engineMiddlewares,
+					AuthMiddlewares:   authMiddlewares,
+					Middlewares:       middlewares,
+					Controllers:       controllers,
+				}
+			},
+			func(cfg *config.Config, e *api.Engine, log *logger.Logger, deps api.Deps) *api.Server {
 				return api.NewServer(api.Config{
 					IsDevEnv:       cfg.IsDevelopmentEnv(),
 					ServiceName:    config.AppName,
 					Addr:           cfg.GetString("servers.api.addr"),
 					DisableAuth:    cfg.GetBoolOrDefaultValue("servers.api.disable_auth", false),
-					AllowOrigin:    cfg.GetString("servers.api.allow_origin"),
+					CORS:           corsConfig(cfg, "servers.api.cors", true),
 					EnableStatsViz: cfg.GetBoolean("servers.api.enable_statsviz"),
 					WriteTimeout:   cfg.GetDuration("servers.api.write_timeout"),
 					ReadTimeout:    cfg.GetDuration("servers.api.read_timeout"),
 				},
-					e, log,
+					e, log, deps,
 				)
 			},
 			func(
@@ -152,13 +363,37 @@ func (a *application) run() {
 			},
 		),
 
-		fx.Invoke(func(log *logger.Logger, db *database.DB, configuration *config.Config) error {
+		fx.Invoke(func(
+			log *logger.Logger,
+			db *database.DB,
+			configuration *config.Config,
+			searchClient *search.Client,
+			indexer *search.Indexer,
+		) error {
 			// *repl.ReplService - is needed because we need run migrations after repl service migration.
 			var err error
 			if err = migration.ApplyMigrations(db, tables.AllDTOs[:]...); err != nil {
 				return fmt.Errorf("migration has not applied: %w", err)
 			}
 
+			rls := configuration.GetBoolOrDefaultValue("tenancy.rls", true)
+			if err = tenancy.CheckRLS(db.DB, rls, tables.TenantScoped[:]...); err != nil {
+				return fmt.Errorf("row level security check: %w", err)
+			}
+
+			// after migrations: they run without tenant.
+			if err = db.Use(tenancy.NewPlugin(tenancy.Config{RLS: rls})); err != nil {
+				return fmt.Errorf("tenancy plugin has not registered: %w", err)
+			}
+
+			if indexer != nil {
+				if err = db.Use(search.NewPlugin(indexer)); err != nil {
+					return fmt.Errorf("search plugin has not registered: %w", err)
+				}
+
+				ensureSearchIndexes(searchClient, log)
+			}
+
 			return nil
 		},
 			a.start),
@@ -180,6 +415,11 @@ func (a *application) start(
 	mServer *metrics.Server,
 	pServer *pprof.Server,
 	apiServer *api.Server,
+	sessions *session.Service,
+	limiter ratelimit.Limiter,
+	indexer *search.Indexer,
+	meter *metering.Service,
+	idempotencyKeys *idempotency.Service,
 ) {
 	lc.Append(fx.Hook{
 		OnStart: func(_ context.Context) error {
@@ -196,6 +436,34 @@ func (a *application) start(
 					"More details you can find in docker compose file -> `docker/docker-compose-dev-local.yml` section `kibana`")
 			}
 
+			errGroup.Go(func() error {
+				return sessions.RunPurger(gCtx)
+			})
+
+			if cfg.GetBoolOrDefaultValue("idempotency.enabled", false) {
+				errGroup.Go(func() error {
+					return idempotencyKeys.RunPurger(gCtx)
+				})
+			}
+
+			if pgLimiter, ok := limiter.(*ratelimit.Postgres); ok {
+				errGroup.Go(func() error {
+					return pgLimiter.RunPurger(gCtx)
+				})
+			}
+
+			if indexer != nil {
+				errGroup.Go(func() error {
+					return indexer.Run(gCtx)
+				})
+			}
+
+			if meter != nil {
+				errGroup.Go(func() error {
+					return meter.Run(gCtx)
+				})
+			}
+
 			apiServer.Run(errGroup, gCtx, appStopTimeout)
 
 			return nil
@@ -212,3 +480,131 @@ func (a *application) start(
 		},
 	})
 }
+
+// ensureSearchIndexes - create indexes of search cluster if they don't exist. Unavailable cluster isn't fatal:
+// search requests fail and documents are dropped by indexer, but the rest of service works.
+func ensureSearchIndexes(client *search.Client, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchIndexesTimeout)
+	defer cancel()
+
+	for index, body := range tables.SearchIndexMappings {
+		if err := client.EnsureIndex(ctx, index, body); err != nil {
+			log.Error("search index has not created", field.String("index", index), field.Error(err))
+		}
+	}
+}
+
+// rateLimitConfig - read rate limiter settings from `servers.api.rate_limit` config section.
+func rateLimitConfig(cfg *config.Config) mw.RateLimitConfig {
+	const path = "servers.api.rate_limit"
+
+	rlCfg := mw.RateLimitConfig{
+		Default: rateLimit(cfg, path+".default."),
+		Routes:  make(map[string]ratelimit.Limit),
+	}
+
+	for _, k := range cfg.GetStringSlice(path + ".key_by") {
+		rlCfg.KeyBy = append(rlCfg.KeyBy, mw.RateLimitKey(k))
+	}
+
+	for route, routeCfg := range cfg.GetConfigMap(path + ".routes") {
+		rlCfg.Routes[route] = rateLimit(routeCfg, "")
+	}
+
+	return rlCfg
+}
+
+// preAuthRateLimitConfig - read limit of private routes before auth from `servers.api.rate_limit.pre_auth`,
+// client isn't authenticated yet, so it's identified by ip.
+func preAuthRateLimitConfig(cfg *config.Config) mw.RateLimitConfig {
+	return mw.RateLimitConfig{
+		Prefix:  "pre_auth:",
+		KeyBy:   []mw.RateLimitKey{mw.RateLimitByIP},
+		Default: rateLimit(cfg, "servers.api.rate_limit.pre_auth."),
+	}
+}
+
+func rateLimit(c *config.Config, prefix string) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: c.GetIntOrDefaultValue(prefix+"requests", 0),
+		Per:      c.GetDuration(prefix + "per"),
+		Burst:    c.GetIntOrDefaultValue(prefix+"burst", 0),
+	}
+}
+
+// meteringConfig - read metering settings from `metering` config section.
+func meteringConfig(cfg *config.Config) metering.Config {
+	mCfg := metering.Config{
+		FlushInterval:   cfg.GetDuration("metering.flush_interval"),
+		RefreshInterval: cfg.GetDuration("metering.refresh_interval"),
+		MaxTenantLabels: cfg.GetIntOrDefaultValue("metering.max_tenant_labels", 0),
+	}
+
+	for metric := range cfg.GetConfigMap("metering.quotas") {
+		for period, periodCfg := range cfg.GetConfigMap("metering.quotas." + metric) {
+			mCfg.Quotas = append(mCfg.Quotas, metering.Quota{
+				Metric: metering.Metric(metric),
+				Period: metering.Period(period),
+				Soft:   int64(periodCfg.GetIntOrDefaultValue("soft", 0)),
+				Hard:   int64(periodCfg.GetIntOrDefaultValue("hard", 0)),
+			})
+		}
+	}
+
+	return mCfg
+}
+
+// loadShedMiddleware - create adaptive concurrency limiter from `servers.api.load_shed` config section.
+func loadShedMiddleware(cfg *config.Config) gin.HandlerFunc {
+	const path = "servers.api.load_shed."
+
+	limiter := concurrency.New(concurrency.Config{
+		InitialLimit:     cfg.GetIntOrDefaultValue(path+"initial_limit", 0),
+		MinLimit:         cfg.GetIntOrDefaultValue(path+"min_limit", 0),
+		MaxLimit:         cfg.GetIntOrDefaultValue(path+"max_limit", 0),
+		LatencyThreshold: cfg.GetDuration(path + "latency_threshold"),
+		MaxQueue:         cfg.GetIntOrDefaultValue(path+"max_queue", 0),
+		QueueTimeout:     cfg.GetDuration(path + "queue_timeout"),
+	})
+
+	return mw.LoadShedMiddleware(limiter, mw.LoadShedConfig{
+		CriticalPaths: cfg.GetStringSlice(path + "critical_paths"),
+		LowPaths:      cfg.GetStringSlice(path + "low_paths"),
+		RetryAfter:    cfg.GetDuration(path + "retry_after"),
+	})
+}
+
+// corsConfig - read CORS settings from config section (per-route overrides are read only for root section).
+func corsConfig(cfg *config.Config, path string, withRoutes bool) mw.CORSConfig {
+	key := func(name string) string {
+		if path == "" {
+			return name
+		}
+
+		return path + "." + name
+	}
+
+	corsCfg := mw.CORSConfig{
+		AllowOrigins:  cfg.GetStringSlice(key("allow_origins")),
+		AllowMethods:  cfg.GetStringSlice(key("allow_methods")),
+		AllowHeaders:  cfg.GetStringSlice(key("allow_headers")),
+		ExposeHeaders: cfg.GetStringSlice(key("expose_headers")),
+		MaxAge:        cfg.GetDuration(key("max_age")),
+	}
+
+	if cfg.Get(key("allow_credentials")) != nil { // not set in route override - default is kept.
+		allowCredentials := cfg.GetBoolean(key("allow_credentials"))
+		corsCfg.AllowCredentials = &allowCredentials
+	}
+
+	if !withRoutes {
+		return corsCfg
+	}
+
+	corsCfg.Routes = make(map[string]mw.CORSConfig)
+	for prefix, routeCfg := range cfg.GetConfigMap(key("routes")) {
+		corsCfg.Routes[prefix] = corsConfig(routeCfg, "", false)
+	}
+
+	return corsCfg
+}
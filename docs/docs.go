@@ -23,6 +23,1289 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/api-keys": {
+            "get": {
+                "description": "List api keys of tenant (without secrets)",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "List api keys",
+                "operationId": "ListAPIKeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id (required for users with ` + "`" + `tenants:manage` + "`" + `, ignored for api keys)",
+                        "name": "tenant_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at, name, expired_at, last_used_at; ` + "`" + `-` + "`" + ` prefix - desc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "` + "`" + `field:op[:value]` + "`" + `, e.g. ` + "`" + `revoked_at:null` + "`" + `",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "full-text search by name and prefix: words, \\",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page cursor from ` + "`" + `Link` + "`" + ` header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_apikey.APIKey"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "next and prev pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create api key for machine client. Full key is returned only once!\nScopes can't be wider than rights of caller. ` + "`" + `roles:manage` + "`" + `, ` + "`" + `tenants:manage` + "`" + ` are granted only to users.\ntenant_id is required for users (` + "`" + `tenants:manage` + "`" + ` permission is needed), ignored for api keys.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "Create api key",
+                "operationId": "CreateAPIKey",
+                "parameters": [
+                    {
+                        "description": "api key",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_apikey.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_apikey.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api-keys/{id}": {
+            "delete": {
+                "description": "Revoke api key of tenant",
+                "tags": [
+                    "API keys"
+                ],
+                "summary": "Revoke api key",
+                "operationId": "RevokeAPIKey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "api key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tenant id (required for users with ` + "`" + `tenants:manage` + "`" + `, ignored for api keys)",
+                        "name": "tenant_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/permissions": {
+            "get": {
+                "description": "List names of all known permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "List permissions",
+                "operationId": "ListPermissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/problems": {
+            "get": {
+                "description": "List all problem types (RFC-7807), which can be returned by service",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Problems"
+                ],
+                "summary": "List problem types",
+                "operationId": "ListProblems",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_problems.Problem"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/problems/{code}": {
+            "get": {
+                "description": "Documentation page of problem type (HTML), or JSON if it's requested by ` + "`" + `Accept` + "`" + ` header",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "Problems"
+                ],
+                "summary": "Problem type documentation",
+                "operationId": "GetProblem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "problem type code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_problems.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/roles": {
+            "get": {
+                "description": "List all roles",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "List roles",
+                "operationId": "ListRoles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_rbac.Role"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new role with given permissions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Create role",
+                "operationId": "CreateRole",
+                "parameters": [
+                    {
+                        "description": "role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_rbac.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_rbac.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/roles/{id}": {
+            "put": {
+                "description": "Update role name and permissions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Update role",
+                "operationId": "UpdateRole",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_rbac.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_rbac.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete role and all its assignments",
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Delete role",
+                "operationId": "DeleteRole",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/search": {
+            "get": {
+                "description": "Search documents of tenant in search index. Index is eventually consistent with DB (about 1s).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search",
+                "operationId": "Search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id (tenant of api key for api keys, users need ` + "`" + `tenants:manage` + "`" + `)",
+                        "name": "tenant_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "index, e.g. api_keys",
+                        "name": "index",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "simple query string: words, \\",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at from (inclusive)",
+                        "name": "from_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at to (exclusive)",
+                        "name": "to_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "time zone of local dates, e.g. Europe/Berlin",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-score",
+                        "description": "score, created_at; ` + "`" + `-` + "`" + ` prefix - desc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "page size",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_search.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions": {
+            "get": {
+                "description": "List active sessions (devices/IPs) of current user",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List sessions",
+                "operationId": "ListSessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_session.Session"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Check credentials, issue new session and set session cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Login",
+                "operationId": "Login",
+                "parameters": [
+                    {
+                        "description": "credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_session.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_session.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke all sessions of current user except current one (optionally only from given ip)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke other sessions",
+                "operationId": "RevokeOtherSessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "revoke only sessions from this ip",
+                        "name": "ip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_session.RevokeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/current": {
+            "delete": {
+                "description": "Revoke current session and clear session cookie",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Logout",
+                "operationId": "Logout",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/{id}": {
+            "delete": {
+                "description": "Revoke one session of current user",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke session",
+                "operationId": "RevokeSession",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tenants": {
+            "get": {
+                "description": "List all tenants (including suspended and deleted)",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "List tenants",
+                "operationId": "ListTenants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register new active tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Create tenant",
+                "operationId": "CreateTenant",
+                "parameters": [
+                    {
+                        "description": "tenant",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tenants/{id}": {
+            "get": {
+                "description": "Get tenant status and settings. Response has ` + "`" + `ETag` + "`" + ` (version of tenant) and ` + "`" + `Last-Modified` + "`" + ` headers,\n304 is returned for ` + "`" + `If-None-Match` + "`" + `/` + "`" + `If-Modified-Since` + "`" + ` if tenant isn't modified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Get tenant",
+                "operationId": "GetTenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of cached tenant",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last modified of cached tenant",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of tenant"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Soft delete tenant: requests of tenant are rejected forever, data is kept.\nWith ` + "`" + `If-Match` + "`" + ` header tenant is deleted only if it isn't modified since (412 otherwise).",
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Delete tenant",
+                "operationId": "DeleteTenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of tenant",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tenants/{id}/resume": {
+            "post": {
+                "description": "Make suspended tenant active",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Resume tenant",
+                "operationId": "ResumeTenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tenants/{id}/settings": {
+            "put": {
+                "description": "Replace settings of tenant (time zone, locale, feature toggles, limits).\nWith ` + "`" + `If-Match` + "`" + ` header settings are replaced only if tenant isn't modified since (412 otherwise).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Update tenant settings",
+                "operationId": "UpdateTenantSettings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of tenant",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tenants/{id}/suspend": {
+            "post": {
+                "description": "Suspend tenant: all requests of tenant are rejected with ` + "`" + `tenant_suspended` + "`" + ` problem until resume",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenants"
+                ],
+                "summary": "Suspend tenant",
+                "operationId": "SuspendTenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_tenant.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/usage": {
+            "get": {
+                "description": "Usage of tenant aggregated by hour or day and current usage of quotas.\nUsage is flushed periodically, so usage of last seconds may be not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Usage of tenant",
+                "operationId": "GetUsage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tenant id (required for users with ` + "`" + `tenants:manage` + "`" + `, ignored for api keys)",
+                        "name": "tenant_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "requests",
+                            "reports",
+                            "storage_bytes"
+                        ],
+                        "type": "string",
+                        "description": "metric, all by default",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "aggregation period",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from (inclusive), last 93 days by default",
+                        "name": "from_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to (exclusive)",
+                        "name": "to_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "time zone of local dates, e.g. Europe/Berlin",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_servers_api_controller_usage.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/roles": {
+            "get": {
+                "description": "List roles assigned to user",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "List user roles",
+                "operationId": "ListUserRoles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_servers_api_controller_rbac.Role"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/roles/{role_id}": {
+            "put": {
+                "description": "Assign role to user",
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Assign role",
+                "operationId": "AssignRole",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove role from user",
+                "tags": [
+                    "RBAC"
+                ],
+                "summary": "Unassign role",
+                "operationId": "UnassignRole",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "role id",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
                 "description": "health check",
@@ -65,6 +1348,463 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "definitions": {
+        "github_com_imperiuse_go-app-skeleton_internal_servers_api_controller_apierror.APIError": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "A human-readable explanation specific to this occurrence.",
+                    "type": "string",
+                    "example": "Description of the problem"
+                },
+                "instance": {
+                    "description": "A URI reference that identifies the specific occurrence of the problem.",
+                    "type": "string",
+                    "example": "GET /api/v1/some"
+                },
+                "request_id": {
+                    "description": "Extension member: id of request, for correlation with logs.",
+                    "type": "string",
+                    "example": "8b6c1f3e-0c2e-4c5e-9a59-3f0f2b1f7d4a"
+                },
+                "status": {
+                    "description": "The HTTP status code for this occurrence of the problem.",
+                    "type": "integer",
+                    "example": 500
+                },
+                "title": {
+                    "description": "A short, human-readable summary of the problem type.",
+                    "type": "string",
+                    "example": "Name of the problem or an error"
+                },
+                "type": {
+                    "description": "A URI reference that identifies the problem type.",
+                    "type": "string",
+                    "example": "/api/v1/problems/internal"
+                }
+            }
+        },
+        "internal_servers_api_controller_apikey.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "description": "escaped name with matched words in \u003cmark\u003e\u003c/mark\u003e.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "full-text search relevance.",
+                    "type": "number"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_servers_api_controller_apikey.CreateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expired_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "description": "required for users, ignored for api keys.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_servers_api_controller_apikey.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "description": "escaped name with matched words in \u003cmark\u003e\u003c/mark\u003e.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "full-text search relevance.",
+                    "type": "number"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_servers_api_controller_problems.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Requested resource doesn't exist"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/api/v1/problems/not_found"
+                }
+            }
+        },
+        "internal_servers_api_controller_rbac.Role": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_servers_api_controller_rbac.RoleRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "permissions"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "permissions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_servers_api_controller_search.Item": {
+            "type": "object",
+            "properties": {
+                "document": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "internal_servers_api_controller_search.Response": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_servers_api_controller_search.Item"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_servers_api_controller_session.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 256
+                }
+            }
+        },
+        "internal_servers_api_controller_session.RevokeResult": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_servers_api_controller_session.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_servers_api_controller_tenant.CreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "settings": {
+                    "$ref": "#/definitions/internal_servers_api_controller_tenant.Settings"
+                }
+            }
+        },
+        "internal_servers_api_controller_tenant.Settings": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "limits": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "locale": {
+                    "type": "string",
+                    "maxLength": 35,
+                    "example": "en-US"
+                },
+                "time_zone": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "internal_servers_api_controller_tenant.Tenant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "settings": {
+                    "$ref": "#/definitions/internal_servers_api_controller_tenant.Settings"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "suspended",
+                        "deleted"
+                    ]
+                },
+                "suspended_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "incremented by each change, it's ` + "`" + `ETag` + "`" + ` of tenant.",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_servers_api_controller_usage.Bucket": {
+            "type": "object",
+            "properties": {
+                "metric": {
+                    "type": "string",
+                    "enum": [
+                        "requests",
+                        "reports",
+                        "storage_bytes"
+                    ]
+                },
+                "start": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_servers_api_controller_usage.Quota": {
+            "type": "object",
+            "properties": {
+                "hard_limit": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "type": "string",
+                    "enum": [
+                        "requests",
+                        "reports",
+                        "storage_bytes"
+                    ]
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "hour",
+                        "day"
+                    ]
+                },
+                "reset_at": {
+                    "type": "string"
+                },
+                "soft_limit": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_servers_api_controller_usage.Report": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "hour",
+                        "day"
+                    ]
+                },
+                "quotas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_servers_api_controller_usage.Quota"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_servers_api_controller_usage.Bucket"
+                    }
+                }
+            }
+        }
     }
 }`
 
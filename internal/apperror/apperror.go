@@ -0,0 +1,132 @@
+// Package apperror - typed application errors, which are converted to RFC-7807 problem details by api server.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error - application error, errors are created from declared problem types (@see Define).
+//   - Code - problem type code (e.g. `not_found`), part of problem type URI.
+//   - Status, Title - http status and short summary of problem type.
+//   - Detail - explanation of this occurrence, it's shown to client.
+//   - Cause - internal error, it's logged, but never shown to client.
+//   - Extensions - additional members of problem details (RFC-7807 extension members).
+type Error struct {
+	Code       string
+	Status     int
+	Title      string
+	Detail     string
+	Cause      error
+	Extensions map[string]any
+}
+
+// BadRequest - bad request error.
+func BadRequest(detail string) *Error {
+	return ErrBadRequest.WithDetail(detail)
+}
+
+// BadQueryParam - bad query param error, name of param is in `param` extension member.
+func BadQueryParam(name string, detail string) *Error {
+	return ErrBadQueryParam.WithDetail(detail).With("param", name)
+}
+
+// BadPathParam - bad path param error, name of param is in `param` extension member.
+func BadPathParam(name string, detail string) *Error {
+	return ErrBadPathParam.WithDetail(detail).With("param", name)
+}
+
+// Unauthorized - unauthorized error.
+func Unauthorized(detail string) *Error {
+	return ErrUnauthorized.WithDetail(detail)
+}
+
+// Forbidden - forbidden error.
+func Forbidden(detail string) *Error {
+	return ErrForbidden.WithDetail(detail)
+}
+
+// NotFound - not found error.
+func NotFound(detail string) *Error {
+	return ErrNotFound.WithDetail(detail)
+}
+
+// Conflict - conflict with existing resource error.
+func Conflict(detail string) *Error {
+	return ErrConflict.WithDetail(detail)
+}
+
+// Internal - internal error, cause is logged and is hidden from client.
+func Internal(cause error) *Error {
+	return ErrInternal.WithCause(cause)
+}
+
+// Error - implements error.
+func (e *Error) Error() string {
+	msg := e.Code + ": " + e.Title
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+
+	return msg
+}
+
+// Unwrap - cause of error, for errors.Is/As.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithDetail - copy of error with detail.
+func (e *Error) WithDetail(detail string) *Error {
+	c := e.clone()
+	c.Detail = detail
+
+	return c
+}
+
+// WithDetailf - copy of error with formatted detail.
+func (e *Error) WithDetailf(format string, args ...any) *Error {
+	return e.WithDetail(fmt.Sprintf(format, args...))
+}
+
+// WithCause - copy of error with cause.
+func (e *Error) WithCause(cause error) *Error {
+	c := e.clone()
+	c.Cause = cause
+
+	return c
+}
+
+// With - copy of error with extension member.
+func (e *Error) With(key string, value any) *Error {
+	c := e.clone()
+
+	c.Extensions = make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		c.Extensions[k] = v
+	}
+
+	c.Extensions[key] = value
+
+	return c
+}
+
+// From - application error of err chain, or internal error with err as cause.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return Internal(err)
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+
+	return &c
+}
@@ -0,0 +1,41 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	base := NotFound("role not found")
+	e := base.With("role_id", 1).WithCause(errors.New("db"))
+
+	assert.Nil(t, base.Extensions, "builders don't modify original")
+	assert.Equal(t, map[string]any{"role_id": 1}, e.Extensions)
+	assert.Equal(t, "not_found: Not found: role not found: db", e.Error())
+
+	wrapped := fmt.Errorf("handler: %w", e)
+	assert.Same(t, e, From(wrapped))
+
+	internal := From(errors.New("boom"))
+	assert.Equal(t, http.StatusInternalServerError, internal.Status)
+	assert.EqualError(t, errors.Unwrap(internal), "boom")
+}
+
+func TestProblemTypes(t *testing.T) {
+	p, ok := LookupProblemType(ErrNotFound.Code)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+
+	types := ProblemTypes()
+	assert.NotEmpty(t, types)
+
+	for _, p := range types {
+		assert.NotEmpty(t, p.Description, p.Code)
+	}
+
+	assert.Panics(t, func() { Define(http.StatusNotFound, ErrNotFound.Code, "", "") })
+}
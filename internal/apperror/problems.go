@@ -0,0 +1,106 @@
+package apperror
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ProblemType - declared problem type, it's documented by api server (@see controller/problems).
+type ProblemType struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+var (
+	problemsMu sync.RWMutex
+	problems   = make(map[string]ProblemType)
+)
+
+// Problem types of service. Each problem type must be declared only once, by Define.
+var (
+	ErrBadRequest = Define(http.StatusBadRequest, "bad_request", "Bad request",
+		"Request body or params are malformed or have invalid values. See `detail` for the reason.")
+	ErrBadQueryParam = Define(http.StatusBadRequest, "bad_query_param", "Bad query param",
+		"Query param is missing or has invalid value. Name of param is in `param` member.")
+	ErrBadPathParam = Define(http.StatusBadRequest, "bad_path_param", "Bad path param",
+		"Path param has invalid value (e.g. id is not a number). Name of param is in `param` member.")
+	ErrValidation = Define(http.StatusUnprocessableEntity, "validation_failed", "Request validation failed",
+		"Request is well-formed, but some fields have invalid values. "+
+			"Every invalid field is listed in `invalid-params` member: name, location, JSON pointer, rule and message.")
+	ErrUnauthorized = Define(http.StatusUnauthorized, "unauthorized", "Unauthorized",
+		"Request is not authenticated: session cookie or `X-AUTH-TOKEN` api key is missing, invalid or expired.")
+	ErrForbidden = Define(http.StatusForbidden, "forbidden", "Forbidden",
+		"Client is authenticated, but has no permissions for the request. Missing permissions are in `detail`.")
+	ErrTenantSuspended = Define(http.StatusForbidden, "tenant_suspended", "Tenant is suspended",
+		"Tenant of request is suspended or deleted by administrator, all its requests are rejected.")
+	ErrNotFound = Define(http.StatusNotFound, "not_found", "Not found",
+		"Requested resource doesn't exist or isn't visible for the client.")
+	ErrNotAcceptable = Define(http.StatusNotAcceptable, "not_acceptable", "Not acceptable",
+		"Response can't be rendered in any media type of `Accept` header. Supported types are in `detail`.")
+	ErrConflict = Define(http.StatusConflict, "conflict", "Conflict",
+		"Resource conflicts with existing one, e.g. its name is already used. See `detail` for the reason.")
+	ErrIdempotencyKeyInUse = Define(http.StatusConflict, "idempotency_key_in_use", "Idempotency key is in use",
+		"Request with the same `Idempotency-Key` is still in progress. Retry later to get its response.")
+	ErrIdempotencyKeyReused = Define(http.StatusConflict, "idempotency_key_reused", "Idempotency key is reused",
+		"`Idempotency-Key` was already used for other request (method, path, query or body differ). "+
+			"Use new key for new request.")
+	ErrPreconditionFailed = Define(http.StatusPreconditionFailed, "precondition_failed", "Precondition failed",
+		"Resource is modified by other request: `If-Match` header doesn't match current `etag` member. "+
+			"Get resource again, apply changes and retry with new etag.")
+	ErrPayloadTooLarge = Define(http.StatusRequestEntityTooLarge, "payload_too_large", "Payload too large",
+		"Request body (decompressed) is larger than limit of service. Limit is in `detail`.")
+	ErrUnsupportedMediaType = Define(http.StatusUnsupportedMediaType, "unsupported_media_type",
+		"Unsupported media type", "`Content-Encoding` of request body isn't supported. Use `gzip` or `identity`.")
+	ErrRateLimited = Define(http.StatusTooManyRequests, "rate_limited", "Too many requests",
+		"Rate limit of client is exceeded. Retry after number of seconds in `Retry-After` header.")
+	ErrQuotaExceeded = Define(http.StatusTooManyRequests, "quota_exceeded", "Quota is exceeded",
+		"Usage quota of tenant is exceeded: `metric`, `period`, `limit` and `used` members describe the quota. "+
+			"Retry after `Retry-After` header seconds (start of next period).")
+	ErrOverloaded = Define(http.StatusServiceUnavailable, "overloaded", "Service is overloaded",
+		"Service sheds load, because too many requests are in flight. Retry after `Retry-After` header seconds.")
+	ErrInternal = Define(http.StatusInternalServerError, "internal", "Internal server error",
+		"Unexpected error on server side. Report `request_id` of response to service team.")
+)
+
+// Define - declare problem type and return template error of it (use With* methods to get error of occurrence).
+// Panics if problem type with same code is already declared.
+func Define(status int, code string, title string, description string) *Error {
+	problemsMu.Lock()
+	defer problemsMu.Unlock()
+
+	if _, ok := problems[code]; ok {
+		panic("apperror: problem type is declared twice: " + code)
+	}
+
+	problems[code] = ProblemType{Code: code, Status: status, Title: title, Description: description}
+
+	return &Error{Code: code, Status: status, Title: title}
+}
+
+// ProblemTypes - all declared problem types, sorted by code.
+func ProblemTypes() []ProblemType {
+	problemsMu.RLock()
+	defer problemsMu.RUnlock()
+
+	result := make([]ProblemType, 0, len(problems))
+	for _, p := range problems {
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+
+	return result
+}
+
+// LookupProblemType - declared problem type by code.
+func LookupProblemType(code string) (ProblemType, bool) {
+	problemsMu.RLock()
+	defer problemsMu.RUnlock()
+
+	p, ok := problems[code]
+
+	return p, ok
+}
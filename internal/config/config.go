@@ -73,7 +73,20 @@ func NewTestConfig(configPath string) (*Config, error) {
 
 // GetString - like GetString redefine standard library get string. Unquote string if necessary.
 func (c *Config) GetString(path string) string {
-	s := c.Config.GetString(path)
+	return unquote(c.Config.GetString(path))
+}
+
+// GetStringSlice - like GetStringSlice redefine standard library get string slice. Unquote strings if necessary.
+func (c *Config) GetStringSlice(path string) []string {
+	ss := c.Config.GetStringSlice(path)
+	for i := range ss {
+		ss[i] = unquote(ss[i])
+	}
+
+	return ss
+}
+
+func unquote(s string) string {
 	if strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) && len(s) > 2 {
 		return s[1 : len(s)-1]
 	}
@@ -125,6 +138,23 @@ func getValueIfExist[T any](c *Config, path string, f func(string) T, defaultVal
 	return f(path)
 }
 
+// GetConfigMap - get sub configs of object by its keys, e.g. for per-route settings. Empty map if path not exist.
+func (c *Config) GetConfigMap(path string) map[string]*Config {
+	result := make(map[string]*Config)
+
+	if c.Get(path) == nil {
+		return result
+	}
+
+	for key, value := range c.GetObject(path) {
+		if obj, ok := value.(hocon.Object); ok {
+			result[unquote(key)] = &Config{Config: obj.ToConfig()}
+		}
+	}
+
+	return result
+}
+
 // IsProductionEnv - is current env Production.
 func (c *Config) IsProductionEnv() bool {
 	return c.GetCurrentEnvironment() == Production
@@ -0,0 +1,22 @@
+// Package dbtest - test helpers of database code.
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// DryRun - postgres gorm DB without connection: statements are built (Statement.SQL, Statement.Vars), but not
+// executed. Results of queries are set by test callbacks, e.g. db.Callback().Query().After("gorm:query").
+func DryRun(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return db
+}
@@ -0,0 +1,29 @@
+package database
+
+import "errors"
+
+// Postgres error codes, @see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// IsUniqueViolation - err is violation of unique constraint or index.
+func IsUniqueViolation(err error) bool {
+	return sqlState(err) == uniqueViolation
+}
+
+// IsForeignKeyViolation - err is violation of foreign key constraint.
+func IsForeignKeyViolation(err error) bool {
+	return sqlState(err) == foreignKeyViolation
+}
+
+// sqlState - postgres error code of err (pgconn.PgError), "" if err isn't postgres error.
+func sqlState(err error) string {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return pgErr.SQLState()
+	}
+
+	return ""
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pgError string
+
+func (e pgError) Error() string { return "pg error " + string(e) }
+
+func (e pgError) SQLState() string { return string(e) }
+
+func TestIsViolation(t *testing.T) {
+	unique := fmt.Errorf("create role: %w", pgError(uniqueViolation))
+	fk := fmt.Errorf("assign role: %w", pgError(foreignKeyViolation))
+
+	assert.True(t, IsUniqueViolation(unique))
+	assert.False(t, IsUniqueViolation(fk))
+	assert.True(t, IsForeignKeyViolation(fk))
+	assert.False(t, IsForeignKeyViolation(errors.New("other")))
+}
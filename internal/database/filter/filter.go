@@ -0,0 +1,376 @@
+// Package filter - per-endpoint allowlist of filterable fields and `filter` query param parser.
+//
+// Syntax: `filter=status:eq:done,created_at:gte:2024-01-01` - comma separated `field:op[:value]` conditions
+// (joined by AND), param may be repeated. Values of `in`/`nin` are separated by `|`, `\` escapes `,` `|` and `\`.
+// `like`/`ilike` use `*` as wildcard. Fields may be nested JSONB paths, e.g. `subject.data.email`.
+// Field names are mapped to columns only by allowlist and values are always bind params, so it's SQL injection safe.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+)
+
+// Param - name of query param with filter expression.
+const Param = "filter"
+
+const (
+	maxConditions  = 16
+	maxInValues    = 100
+	maxValueLength = 1024
+)
+
+// Type - type of field value.
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Time
+	UUID
+	Bool
+)
+
+// Op - comparison operator.
+type Op string
+
+const (
+	Eq      Op = "eq"
+	Ne      Op = "ne"
+	Gt      Op = "gt"
+	Gte     Op = "gte"
+	Lt      Op = "lt"
+	Lte     Op = "lte"
+	In      Op = "in"
+	Nin     Op = "nin"
+	Like    Op = "like"
+	ILike   Op = "ilike"
+	IsNull  Op = "null"
+	NotNull Op = "notnull"
+)
+
+var (
+	sqlOps = map[Op]string{Eq: "=", Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<=", In: "IN", Nin: "NOT IN",
+		Like: "LIKE", ILike: "ILIKE", IsNull: "IS NULL", NotNull: "IS NOT NULL"}
+
+	typeOps = map[Type][]Op{
+		String: {Eq, Ne, In, Nin, Like, ILike, IsNull, NotNull},
+		Int:    {Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, IsNull, NotNull},
+		Time:   {Eq, Ne, Gt, Gte, Lt, Lte, IsNull, NotNull},
+		UUID:   {Eq, Ne, In, Nin, IsNull, NotNull},
+		Bool:   {Eq, Ne, IsNull, NotNull},
+	}
+
+	// jsonCasts - casts of JSONB text value (#>>) to type of filter value.
+	jsonCasts = map[Type]string{Int: "::bigint", Time: "::timestamptz", UUID: "::uuid", Bool: "::boolean"}
+
+	typeNames = map[Type]string{
+		String: "string", Int: "integer", Time: "RFC3339 time or date", UUID: "uuid", Bool: "boolean",
+	}
+
+	jsonKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+)
+
+type (
+	// Field - filterable field: column and type of value.
+	// Path - JSONB path inside column, Nested - any (client defined) JSONB path inside column is allowed.
+	Field struct {
+		Column string
+		Path   []string
+		Type   Type
+		Nested bool
+	}
+
+	// Spec - filterable fields of one endpoint, key is api name.
+	Spec struct {
+		fields map[string]Field
+	}
+
+	// Condition - one parsed condition.
+	Condition struct {
+		Field  string // api name.
+		Column string
+		Path   []string
+		Type   Type
+		Op     Op
+		Values []any
+	}
+
+	// Filter - parsed filter expression, conditions are joined by AND.
+	Filter []Condition
+)
+
+// NewSpec - constructor of Spec.
+func NewSpec(fields map[string]Field) *Spec {
+	s := &Spec{fields: make(map[string]Field, len(fields))}
+	for name, f := range fields {
+		s.fields[name] = f
+	}
+
+	return s
+}
+
+// Fields - allowed api names, sorted. Nested fields are shown as `name.*`.
+func (s *Spec) Fields() []string {
+	names := make([]string, 0, len(s.fields))
+
+	for name, f := range s.fields {
+		if f.Nested {
+			name += ".*"
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Parse - parse filter expressions (values of repeated `filter` param). Any error is 400 (bad query param) apperror.
+func (s *Spec) Parse(exprs ...string) (Filter, error) {
+	var result Filter
+
+	for _, expr := range exprs {
+		for _, part := range split(expr, ',') {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+
+			if len(result) == maxConditions {
+				return nil, badParam("at most %d filter conditions are allowed", maxConditions)
+			}
+
+			cond, err := s.parseCondition(part)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, cond)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Spec) parseCondition(expr string) (Condition, error) {
+	parts := strings.SplitN(expr, ":", 3) //nolint:gomnd // field:op:value.
+	if len(parts) < 2 {                   //nolint:gomnd // field:op.
+		return Condition{}, badParam("condition `%s` must be `field:op:value`", expr)
+	}
+
+	name, op := strings.TrimSpace(parts[0]), Op(strings.ToLower(strings.TrimSpace(parts[1])))
+
+	f, ok := s.lookup(name)
+	if !ok {
+		return Condition{}, badParam("unknown filter field `%s`, allowed: %s", name, strings.Join(s.Fields(), ", "))
+	}
+
+	if !allowed(f.Type, op) {
+		return Condition{}, badParam("operator `%s` is not allowed for field `%s`, allowed: %s",
+			op, name, joinOps(typeOps[f.Type]))
+	}
+
+	cond := Condition{Field: name, Column: f.Column, Path: f.Path, Type: f.Type, Op: op}
+
+	if op == IsNull || op == NotNull {
+		if len(parts) == 3 { //nolint:gomnd // with value.
+			return Condition{}, badParam("operator `%s` of field `%s` has no value", op, name)
+		}
+
+		return cond, nil
+	}
+
+	if len(parts) < 3 { //nolint:gomnd // without value.
+		return Condition{}, badParam("operator `%s` of field `%s` requires value", op, name)
+	}
+
+	raw := []string{parts[2]}
+	if op == In || op == Nin {
+		raw = split(parts[2], '|')
+		if len(raw) > maxInValues {
+			return Condition{}, badParam("at most %d values are allowed for `%s` of field `%s`", maxInValues, op, name)
+		}
+	}
+
+	for _, r := range raw {
+		v, err := convert(f.Type, unescape(r))
+		if err != nil {
+			return Condition{}, badParam("value `%s` of field `%s` must be %s", r, name, typeNames[f.Type])
+		}
+
+		if op == Like || op == ILike {
+			v = likeEscaper.Replace(v.(string)) //nolint:forcetypeassert // like is allowed only for strings.
+		}
+
+		cond.Values = append(cond.Values, v)
+	}
+
+	return cond, nil
+}
+
+// lookup - field by exact api name or by nested prefix (`subject.data.email` -> `subject` with path data,email).
+func (s *Spec) lookup(name string) (Field, bool) {
+	if f, ok := s.fields[name]; ok && !f.Nested {
+		return f, true
+	}
+
+	prefix, rest, found := strings.Cut(name, ".")
+	for found {
+		if f, ok := s.fields[prefix]; ok && f.Nested {
+			path := strings.Split(rest, ".")
+			for _, key := range path {
+				if !jsonKeyRegexp.MatchString(key) {
+					return Field{}, false
+				}
+			}
+
+			f.Path = append(append([]string(nil), f.Path...), path...)
+
+			return f, true
+		}
+
+		var next string
+
+		next, rest, found = strings.Cut(rest, ".")
+		prefix += "." + next
+	}
+
+	return Field{}, false
+}
+
+// Apply - add WHERE conditions to query.
+func (f Filter) Apply(db *gorm.DB) *gorm.DB {
+	for _, c := range f {
+		db = db.Where(c.expr(db))
+	}
+
+	return db
+}
+
+// Scope - Apply as gorm scope, usage: db.Scopes(filter.Scope).Find(&rows).
+func (f Filter) Scope(db *gorm.DB) *gorm.DB {
+	return f.Apply(db)
+}
+
+func (c Condition) expr(db *gorm.DB) clause.Expr {
+	var (
+		col  = db.Statement.Quote(c.Column)
+		vars []any
+	)
+
+	if len(c.Path) > 0 {
+		// text array literal, keys are validated by jsonKeyRegexp or defined by developer.
+		col = "(" + col + " #>> ?)" + jsonCasts[c.Type]
+		vars = append(vars, "{"+strings.Join(c.Path, ",")+"}")
+	}
+
+	op := sqlOps[c.Op]
+
+	switch c.Op {
+	case IsNull, NotNull:
+		return clause.Expr{SQL: col + " " + op, Vars: vars}
+	case In, Nin:
+		return clause.Expr{SQL: col + " " + op + " ?", Vars: append(vars, c.Values)}
+	case Like, ILike:
+		return clause.Expr{SQL: col + " " + op + ` ? ESCAPE '\'`, Vars: append(vars, c.Values...)}
+	default:
+		return clause.Expr{SQL: col + " " + op + " ?", Vars: append(vars, c.Values...)}
+	}
+}
+
+func convert(t Type, s string) (any, error) {
+	if len(s) > maxValueLength {
+		return nil, fmt.Errorf("value is too long")
+	}
+
+	switch t {
+	case Int:
+		return strconv.ParseInt(s, 10, 64)
+	case Time:
+		if v, err := time.Parse(time.RFC3339, s); err == nil {
+			return v, nil
+		}
+
+		return time.Parse(time.DateOnly, s)
+	case UUID:
+		return uuid.Parse(s)
+	case Bool:
+		return strconv.ParseBool(s)
+	default:
+		return s, nil
+	}
+}
+
+// split - split s by sep, skipping escaped (`\`) separators. Escapes are kept, @see unescape.
+func split(s string, sep byte) []string {
+	var (
+		result []string
+		start  int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(result, s[start:])
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func allowed(t Type, op Op) bool {
+	for _, o := range typeOps[t] {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinOps(ops []Op) string {
+	names := make([]string, 0, len(ops))
+	for _, o := range ops {
+		names = append(names, string(o))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func badParam(format string, args ...any) *apperror.Error {
+	return apperror.BadQueryParam(Param, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+)
+
+var spec = NewSpec(map[string]Field{
+	"id":         {Column: "id", Type: Int},
+	"status":     {Column: "status", Type: String},
+	"created_at": {Column: "created_at", Type: Time},
+	"email":      {Column: "subject", Path: []string{"data", "email"}, Type: String},
+	"subject":    {Column: "subject", Type: String, Nested: true},
+})
+
+func TestSpec_Parse(t *testing.T) {
+	f, err := spec.Parse("status:eq:done,created_at:gte:2024-01-01", `id:in:1|2,status:like:a\,b_*`)
+	require.NoError(t, err)
+	assert.Equal(t, Filter{
+		{Field: "status", Column: "status", Type: String, Op: Eq, Values: []any{"done"}},
+		{Field: "created_at", Column: "created_at", Type: Time, Op: Gte,
+			Values: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Field: "id", Column: "id", Type: Int, Op: In, Values: []any{int64(1), int64(2)}},
+		{Field: "status", Column: "status", Type: String, Op: Like, Values: []any{`a,b\_%`}},
+	}, f)
+
+	f, err = spec.Parse("subject.data.email:null,created_at:lt:2024-01-01T10:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data", "email"}, f[0].Path)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), f[1].Values[0])
+
+	for _, expr := range []string{
+		"password:eq:1",            // unknown field.
+		"status:gt:a",              // op is not allowed for type.
+		"id:eq:one",                // type check.
+		"id:eq",                    // no value.
+		"status:null:true",         // redundant value.
+		"status",                   // no op.
+		"subject.data;drop:eq:x",   // invalid JSON key.
+		"subject:eq:x",             // nested field requires path.
+		"created_at:gte:yesterday", // not a time.
+		"status:eq:1,status:eq:2,id:eq:3,id:eq:4,id:eq:5,id:eq:6,id:eq:7,id:eq:8,id:eq:9,id:eq:10," +
+			"id:eq:11,id:eq:12,id:eq:13,id:eq:14,id:eq:15,id:eq:16,id:eq:17", // too many conditions.
+	} {
+		_, err = spec.Parse(expr)
+		assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code, expr)
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	f, err := spec.Parse("status:nin:a|b,email:ilike:*@x.com,subject.age:notnull,id:lte:5")
+	require.NoError(t, err)
+
+	var rows []struct{ ID int }
+	stmt := f.Apply(db.Table("reports")).Find(&rows).Statement
+
+	assert.Equal(t, `SELECT * FROM "reports" WHERE "status" NOT IN ($1,$2) AND `+
+		`("subject" #>> $3) ILIKE $4 ESCAPE '\' AND ("subject" #>> $5) IS NOT NULL AND "id" <= $6`, stmt.SQL.String())
+	assert.Equal(t, []any{"a", "b", "{data,email}", "%@x.com", "{age}", int64(5)}, stmt.Vars)
+}
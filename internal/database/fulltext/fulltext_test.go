@@ -0,0 +1,75 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+)
+
+func TestParse(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		"":                                 "",
+		"  error   timeout ":               "'error' & 'timeout'",
+		`"connection reset" by peer`:       "('connection' <-> 'reset') & 'by' & 'peer'",
+		"time* -debug":                     "'time':* & !'debug'",
+		"error OR warning OR fatal status": "('error' | 'warning' | 'fatal') & 'status'",
+		"OR x-ray*":                        "('x' <-> 'ray':*)",
+		"'; DROP TABLE users; -- ":         "'DROP' & 'TABLE' & 'users'",
+		`"unterminated phrase`:             "('unterminated' <-> 'phrase')",
+		"привет мир":                       "'привет' & 'мир'",
+	} {
+		assert.Equal(t, expected, Parse(pattern).String(), pattern)
+	}
+}
+
+func TestIndex_DDL(t *testing.T) {
+	idx := Index{Table: "reports", Language: "english", Fields: []Field{{"title", 'A'}, {"body", 0}}}
+
+	stmts, err := idx.DDL()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE reports ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (" +
+			"setweight(to_tsvector('english'::regconfig, COALESCE(title, '')), 'A') || " +
+			"setweight(to_tsvector('english'::regconfig, COALESCE(body, '')), 'D')) STORED",
+		"CREATE INDEX IF NOT EXISTS idx__reports__search_vector ON reports USING GIN (search_vector)",
+	}, stmts)
+
+	idx.Mode, idx.LanguageColumn = Trigger, "lang"
+
+	stmts, err = idx.DDL()
+	require.NoError(t, err)
+	assert.Len(t, stmts, 5)
+	assert.Contains(t, stmts[1], "NEW.search_vector := setweight(to_tsvector(COALESCE(NEW.lang, 'english'::regconfig), "+
+		"COALESCE(NEW.title, '')), 'A')")
+
+	_, err = Index{Table: "reports; --", Fields: []Field{{"title", 'A'}}}.DDL()
+	assert.Error(t, err)
+
+	_, err = Index{Table: "reports", LanguageColumn: "lang", Fields: []Field{{"title", 'A'}}}.DDL()
+	assert.Error(t, err, "generated column can't have per-row language")
+}
+
+func TestIndex_Scope(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	idx := Index{Table: "reports", Fields: []Field{{"title", 'A'}}}
+	q := Parse("err*")
+
+	var rows []struct{ ID int }
+	stmt := db.Table("reports").Select("*, ? AS rank", idx.Rank(q)).Scopes(idx.Scope(q)).Find(&rows).Statement
+
+	assert.Equal(t, `SELECT *, ts_rank_cd("search_vector", to_tsquery($1::regconfig, $2)) AS rank FROM "reports" `+
+		`WHERE "search_vector" @@ to_tsquery($3::regconfig, $4)`, stmt.SQL.String())
+	assert.Equal(t, []any{"simple", "'err':*", "simple", "'err':*"}, stmt.Vars)
+
+	stmt = db.Table("reports").Select("? AS highlight", idx.Headline("title", q)).Find(&rows).Statement
+	assert.Equal(t, `SELECT ts_headline($1::regconfig, replace(replace(replace(replace(replace("title", '&', '&amp;'), `+
+		`'<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'), to_tsquery($2::regconfig, $3), $4) AS highlight `+
+		`FROM "reports"`, stmt.SQL.String())
+
+	stmt = db.Table("reports").Scopes(idx.Scope(Parse(""))).Find(&rows).Statement
+	assert.Equal(t, `SELECT * FROM "reports"`, stmt.SQL.String())
+}
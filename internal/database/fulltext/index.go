@@ -0,0 +1,155 @@
+// Package fulltext - postgres full-text search: tsvector columns of models and `search` query param.
+package fulltext
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mode - how tsvector column is maintained.
+type Mode int
+
+const (
+	// Generated - `GENERATED ALWAYS AS (...) STORED` column, language is the same for all rows.
+	Generated Mode = iota
+	// Trigger - column updated by trigger, allows per-row language (LanguageColumn).
+	Trigger
+)
+
+// DefaultColumn - default name of tsvector column.
+const DefaultColumn = "search_vector"
+
+var identRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+type (
+	// Field - searchable text column and its weight (A - most important ... D).
+	Field struct {
+		Column string
+		Weight byte
+	}
+
+	// Index - full-text index of table (model).
+	Index struct {
+		Table  string
+		Column string // tsvector column, DefaultColumn if empty.
+		Mode   Mode
+		// Language - text search configuration (`simple`, `english`, `russian`, ...), `simple` if empty.
+		Language string
+		// LanguageColumn - regconfig column with language of row, only for Trigger mode.
+		LanguageColumn string
+		Fields         []Field
+	}
+)
+
+// DDL - idempotent statements which create tsvector column, GIN index and (for Trigger mode) trigger,
+// they are copied to up migration of index (migrations/migrations). NB! Changed Fields of existing Generated
+// column are not applied - migration must drop column for that.
+func (idx Index) DDL() ([]string, error) {
+	if err := idx.validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		table  = idx.Table
+		column = idx.column()
+		gin    = fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx__%s__%s ON %s USING GIN (%s)",
+			table, column, table, column)
+	)
+
+	if idx.Mode == Generated {
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (%s) STORED",
+				table, column, idx.vectorExpr("", "'"+idx.language()+"'::regconfig")),
+			gin,
+		}, nil
+	}
+
+	lang := "'" + idx.language() + "'::regconfig"
+	if idx.LanguageColumn != "" {
+		lang = fmt.Sprintf("COALESCE(NEW.%s, %s)", idx.LanguageColumn, lang)
+	}
+
+	fn := fmt.Sprintf("%s__%s__update", table, column)
+
+	return []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector", table, column),
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$
+BEGIN
+    NEW.%s := %s;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql`, fn, column, idx.vectorExpr("NEW.", lang)),
+		fmt.Sprintf("DROP TRIGGER IF EXISTS trg__%s ON %s", fn, table),
+		fmt.Sprintf("CREATE TRIGGER trg__%s BEFORE INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+			fn, table, fn),
+		gin,
+	}, nil
+}
+
+// vectorExpr - concatenation of weighted (setweight) to_tsvector of fields.
+func (idx Index) vectorExpr(prefix, lang string) string {
+	parts := make([]string, 0, len(idx.Fields))
+	for _, f := range idx.Fields {
+		parts = append(parts, fmt.Sprintf("setweight(to_tsvector(%s, COALESCE(%s%s, '')), '%c')",
+			lang, prefix, f.Column, f.weight()))
+	}
+
+	return strings.Join(parts, " || ")
+}
+
+func (idx Index) validate() error {
+	idents := []string{idx.Table, idx.column(), idx.language()}
+	if idx.LanguageColumn != "" {
+		if idx.Mode != Trigger {
+			return fmt.Errorf("fulltext index of %s: LanguageColumn requires Trigger mode", idx.Table)
+		}
+
+		idents = append(idents, idx.LanguageColumn)
+	}
+
+	if len(idx.Fields) == 0 {
+		return fmt.Errorf("fulltext index of %s: no fields", idx.Table)
+	}
+
+	for _, f := range idx.Fields {
+		if w := f.weight(); w < 'A' || w > 'D' {
+			return fmt.Errorf("fulltext index of %s: weight of %s must be A-D", idx.Table, f.Column)
+		}
+
+		idents = append(idents, f.Column)
+	}
+
+	// DDL can't have bind params, so all names are checked.
+	for _, ident := range idents {
+		if !identRegexp.MatchString(ident) {
+			return fmt.Errorf("fulltext index of %s: invalid identifier %q", idx.Table, ident)
+		}
+	}
+
+	return nil
+}
+
+func (idx Index) column() string {
+	if idx.Column == "" {
+		return DefaultColumn
+	}
+
+	return idx.Column
+}
+
+func (idx Index) language() string {
+	if idx.Language == "" {
+		return "simple"
+	}
+
+	return idx.Language
+}
+
+func (f Field) weight() byte {
+	if f.Weight == 0 {
+		return 'D'
+	}
+
+	return f.Weight
+}
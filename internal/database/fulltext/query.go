@@ -0,0 +1,200 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxTerms = 32
+
+	// headlineOptions - options of ts_headline, client renders <mark> itself.
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+	// escapeHTML - html escaping of text (like html.EscapeString) before ts_headline.
+	escapeHTML = `replace(replace(replace(replace(replace(?, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), ` +
+		`'"', '&#34;'), '''', '&#39;')`
+)
+
+// Query - parsed search pattern as to_tsquery text. Zero Query matches everything.
+//
+// Syntax (like web search engines): `error timeout` - both words, `"connection reset"` - phrase,
+// `time*` - prefix, `-debug` - without word, `error OR warning` - any of words.
+type Query struct {
+	text string
+}
+
+type term struct {
+	words  []string // several words - phrase.
+	prefix bool
+	not    bool
+}
+
+// Parse - parse search pattern. Only letters and digits of words are kept, so result is always valid tsquery.
+func Parse(s string) Query {
+	var (
+		groups [][]term // AND of OR groups.
+		or     bool
+		count  int
+	)
+
+	for _, t := range tokenize(s) {
+		if count == maxTerms {
+			break
+		}
+
+		if len(t.words) == 1 && t.words[0] == "OR" && !t.not && !t.prefix {
+			or = len(groups) > 0
+
+			continue
+		}
+
+		if or {
+			groups[len(groups)-1] = append(groups[len(groups)-1], t)
+		} else {
+			groups = append(groups, []term{t})
+		}
+
+		or = false
+		count++
+	}
+
+	parts := make([]string, 0, len(groups))
+
+	for _, g := range groups {
+		alts := make([]string, 0, len(g))
+		for _, t := range g {
+			alts = append(alts, t.String())
+		}
+
+		if len(alts) == 1 {
+			parts = append(parts, alts[0])
+		} else {
+			parts = append(parts, "("+strings.Join(alts, " | ")+")")
+		}
+	}
+
+	return Query{text: strings.Join(parts, " & ")}
+}
+
+// IsZero - empty query.
+func (q Query) IsZero() bool {
+	return q.text == ""
+}
+
+// String - tsquery text.
+func (q Query) String() string {
+	return q.text
+}
+
+// Match - condition `row matches query`.
+func (idx Index) Match(q Query) clause.Expr {
+	return clause.Expr{
+		SQL:  "? @@ to_tsquery(?::regconfig, ?)",
+		Vars: []any{clause.Column{Name: idx.column()}, idx.language(), q.text},
+	}
+}
+
+// Rank - relevance of row (ts_rank_cd), e.g. db.Select("*, ? AS rank", idx.Rank(q)).
+func (idx Index) Rank(q Query) clause.Expr {
+	return clause.Expr{
+		SQL:  "ts_rank_cd(?, to_tsquery(?::regconfig, ?))",
+		Vars: []any{clause.Column{Name: idx.column()}, idx.language(), q.text},
+	}
+}
+
+// Headline - HTML-escaped text of column with matched words wrapped into <mark></mark> (ts_headline),
+// so it's safe to render as HTML: the only tags are <mark>.
+func (idx Index) Headline(column string, q Query) clause.Expr {
+	return clause.Expr{
+		SQL:  "ts_headline(?::regconfig, " + escapeHTML + ", to_tsquery(?::regconfig, ?), ?)",
+		Vars: []any{idx.language(), clause.Column{Name: column}, idx.language(), q.text, headlineOptions},
+	}
+}
+
+// Scope - gorm scope with Match condition, zero query doesn't filter anything.
+func (idx Index) Scope(q Query) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if q.IsZero() {
+			return db
+		}
+
+		return db.Where(idx.Match(q))
+	}
+}
+
+func (t term) String() string {
+	lexemes := make([]string, 0, len(t.words))
+	for _, w := range t.words {
+		lexemes = append(lexemes, "'"+w+"'")
+	}
+
+	if t.prefix {
+		lexemes[len(lexemes)-1] += ":*"
+	}
+
+	s := strings.Join(lexemes, " <-> ")
+	if len(lexemes) > 1 {
+		s = "(" + s + ")"
+	}
+
+	if t.not {
+		s = "!" + s
+	}
+
+	return s
+}
+
+// tokenize - split pattern to terms: words, quoted phrases, with `-` and `*` markers.
+func tokenize(s string) []term {
+	var (
+		result []term
+		rs     = []rune(s)
+	)
+
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+
+			continue
+		}
+
+		var t term
+		if rs[i] == '-' {
+			t.not = true
+			i++
+		}
+
+		var raw string
+
+		if i < len(rs) && rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+
+			raw = string(rs[i+1 : min(end, len(rs))])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) {
+				end++
+			}
+
+			raw = string(rs[i:end])
+			i = end
+			t.prefix = strings.HasSuffix(raw, "*")
+		}
+
+		// `foo-bar`, `a.b` are phrases of words, as postgres parser does.
+		t.words = strings.FieldsFunc(raw, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if len(t.words) > 0 {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
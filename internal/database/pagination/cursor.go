@@ -0,0 +1,177 @@
+// Package pagination - keyset (seek) pagination with opaque signed cursors.
+//
+// Cursor holds sort key values of the edge row of a page, so next page is selected by `WHERE (keys) > (cursor)`
+// instead of slow OFFSET. Cursor is bound to sort expression, @see sorting.Sort, and signed (HMAC-SHA256),
+// so client can't forge key values, but it can (and must) only pass it back as is.
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+)
+
+// Param - name of query param with cursor.
+const Param = "cursor"
+
+const (
+	version        = 1
+	maxTokenLength = 4096
+	secretLength   = 32
+)
+
+// Direction - direction of page relative to cursor.
+type Direction string
+
+const (
+	Next Direction = "next"
+	Prev Direction = "prev"
+)
+
+var (
+	errMalformed = errors.New("malformed cursor")
+	errSignature = errors.New("invalid cursor signature")
+)
+
+type (
+	// Codec - encode and decode (with signature check) cursors.
+	Codec struct {
+		secret []byte
+	}
+
+	// Cursor - decoded cursor: sort key values of edge row of page.
+	Cursor struct {
+		Direction Direction
+		Values    []any // values of sorting.Sort columns, same order.
+	}
+
+	payload struct {
+		Version   int       `json:"v"`
+		Direction Direction `json:"d"`
+		Sort      string    `json:"s"`
+		Values    []any     `json:"k"`
+	}
+)
+
+// NewCodec - constructor of Codec. Empty secret means random one, then cursors are valid only for this process.
+func NewCodec(secret []byte) (*Codec, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, secretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate cursor secret: %w", err)
+		}
+	}
+
+	return &Codec{secret: secret}, nil
+}
+
+// Encode - encode cursor of given sort to opaque url-safe token.
+func (c *Codec) Encode(order sorting.Sort, cursor Cursor) (string, error) {
+	data, err := json.Marshal(payload{
+		Version:   version,
+		Direction: cursor.Direction,
+		Sort:      sortKey(order),
+		Values:    cursor.Values,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(c.sign(data)), nil
+}
+
+// Decode - decode token and check that it was issued for given sort. Any error is 400 (bad query param) apperror.
+func (c *Codec) Decode(token string, order sorting.Sort) (*Cursor, error) {
+	cursor, err := c.decode(token, order)
+	if err != nil {
+		return nil, apperror.BadQueryParam(Param, err.Error()).WithCause(err)
+	}
+
+	return cursor, nil
+}
+
+func (c *Codec) decode(token string, order sorting.Sort) (*Cursor, error) {
+	if len(token) > maxTokenLength {
+		return nil, errMalformed
+	}
+
+	encodedData, encodedSign, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errMalformed
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encodedData)
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	sign, err := base64.RawURLEncoding.DecodeString(encodedSign)
+	if err != nil {
+		return nil, errMalformed
+	}
+
+	if !hmac.Equal(sign, c.sign(data)) {
+		return nil, errSignature
+	}
+
+	var p payload
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err = dec.Decode(&p); err != nil {
+		return nil, errMalformed
+	}
+
+	switch {
+	case p.Version != version:
+		return nil, fmt.Errorf("unsupported cursor version %d", p.Version)
+	case p.Direction != Next && p.Direction != Prev:
+		return nil, errMalformed
+	case p.Sort != sortKey(order) || len(p.Values) != len(order):
+		return nil, errors.New("cursor doesn't match `sort`, start from first page")
+	}
+
+	for i, v := range p.Values {
+		switch v := v.(type) {
+		case json.Number:
+			p.Values[i] = v.String() // text param, postgres casts it to type of column.
+		case string, bool, nil:
+		default:
+			return nil, errMalformed
+		}
+	}
+
+	return &Cursor{Direction: p.Direction, Values: p.Values}, nil
+}
+
+func (c *Codec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// sortKey - sort expression by columns, including tie breaker (unlike sorting.Sort.String).
+func sortKey(order sorting.Sort) string {
+	parts := make([]string, 0, len(order))
+
+	for _, o := range order {
+		if o.Desc {
+			parts = append(parts, "-"+o.Column)
+		} else {
+			parts = append(parts, o.Column)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
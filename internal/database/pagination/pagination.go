@@ -0,0 +1,185 @@
+package pagination
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+)
+
+type (
+	// Request - page request: sort, optional cursor and page size.
+	Request struct {
+		Order  sorting.Sort
+		Cursor *Cursor // nil - first page.
+		Limit  int
+
+		codec *Codec
+	}
+
+	// Page - rows of page and cursors of neighbour pages (empty if there is no such page).
+	Page[T any] struct {
+		Items []T
+		Next  string
+		Prev  string
+	}
+)
+
+var schemaCache = &sync.Map{}
+
+// NewRequest - decode and validate cursor token (may be empty) against order.
+func (c *Codec) NewRequest(order sorting.Sort, token string, limit int) (Request, error) {
+	req := Request{Order: order, Limit: limit, codec: c}
+
+	if token != "" {
+		cursor, err := c.Decode(token, order)
+		if err != nil {
+			return req, err
+		}
+
+		req.Cursor = cursor
+	}
+
+	return req, nil
+}
+
+// Find - select page of T rows by keyset pagination. Query db may already have conditions, but not ORDER BY.
+func Find[T any](db *gorm.DB, req Request) (Page[T], error) {
+	var page Page[T]
+
+	backward := req.Cursor != nil && req.Cursor.Direction == Prev
+
+	order := req.Order
+	if backward {
+		order = reversed(order)
+	}
+
+	if req.Cursor != nil {
+		db = db.Where(after(db, order, req.Cursor.Values))
+	}
+
+	var rows []T
+	if err := order.Apply(db).Limit(req.Limit + 1).Find(&rows).Error; err != nil {
+		return page, err
+	}
+
+	hasMore := len(rows) > req.Limit
+	if hasMore {
+		rows = rows[:req.Limit]
+	}
+
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page.Items = rows
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	var err error
+
+	if hasMore || backward {
+		if page.Next, err = req.cursor(db, Next, &rows[len(rows)-1]); err != nil {
+			return page, err
+		}
+	}
+
+	if (hasMore && backward) || (req.Cursor != nil && !backward) {
+		if page.Prev, err = req.cursor(db, Prev, &rows[0]); err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// cursor - encode cursor with sort key values of row (pointer to gorm model).
+func (r Request) cursor(db *gorm.DB, dir Direction, row any) (string, error) {
+	sch, err := schema.Parse(row, schemaCache, db.NamingStrategy)
+	if err != nil {
+		return "", fmt.Errorf("parse schema for cursor: %w", err)
+	}
+
+	rv := reflect.ValueOf(row)
+	values := make([]any, 0, len(r.Order))
+
+	for _, o := range r.Order {
+		f := sch.LookUpField(o.Column)
+		if f == nil {
+			return "", fmt.Errorf("cursor: column %q not found in %s", o.Column, sch.Name)
+		}
+
+		v, _ := f.ValueOf(db.Statement.Context, rv)
+		values = append(values, v)
+	}
+
+	return r.codec.Encode(r.Order, Cursor{Direction: dir, Values: values})
+}
+
+// after - condition `row is after keys in given order`, expanded to
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ..., with postgres NULL ordering (NULLS LAST for ASC, FIRST for DESC).
+func after(db *gorm.DB, order sorting.Sort, keys []any) clause.Expr {
+	var (
+		sql  strings.Builder
+		vars []any
+		eq   []string
+		eqV  []any
+	)
+
+	for i, o := range order {
+		col := db.Statement.Quote(o.Column)
+
+		var cond string
+
+		var condV []any
+
+		switch {
+		case !o.Desc && keys[i] != nil:
+			cond, condV = fmt.Sprintf("(%s > ? OR %s IS NULL)", col, col), []any{keys[i]}
+		case o.Desc && keys[i] != nil:
+			cond, condV = col+" < ?", []any{keys[i]}
+		case o.Desc:
+			cond = col + " IS NOT NULL"
+		}
+
+		if cond != "" {
+			if sql.Len() > 0 {
+				sql.WriteString(" OR ")
+			}
+
+			sql.WriteString("(" + strings.Join(append(slices.Clone(eq), cond), " AND ") + ")")
+			vars = append(append(vars, eqV...), condV...)
+		}
+
+		if keys[i] == nil {
+			eq = append(eq, col+" IS NULL")
+		} else {
+			eq = append(eq, col+" = ?")
+			eqV = append(eqV, keys[i])
+		}
+	}
+
+	if sql.Len() == 0 {
+		return clause.Expr{SQL: "FALSE"}
+	}
+
+	return clause.Expr{SQL: "(" + sql.String() + ")", Vars: vars}
+}
+
+func reversed(order sorting.Sort) sorting.Sort {
+	result := make(sorting.Sort, len(order))
+	for i, o := range order {
+		o.Desc = !o.Desc
+		result[i] = o
+	}
+
+	return result
+}
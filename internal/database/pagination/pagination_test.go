@@ -0,0 +1,88 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+)
+
+var spec = sorting.NewSpec(map[string]string{
+	"created_at": "created_at",
+	"used_at":    "last_used_at",
+}, "id", "-created_at")
+
+func TestCodec(t *testing.T) {
+	codec, err := NewCodec([]byte("secret"))
+	require.NoError(t, err)
+
+	order, err := spec.Parse("")
+	require.NoError(t, err)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	token, err := codec.Encode(order, Cursor{Direction: Next, Values: []any{createdAt, 42}})
+	require.NoError(t, err)
+
+	req, err := codec.NewRequest(order, token, 10)
+	require.NoError(t, err)
+	assert.Equal(t, &Cursor{Direction: Next, Values: []any{"2024-01-02T03:04:05.000006Z", "42"}}, req.Cursor)
+
+	other, err := NewCodec(nil)
+	require.NoError(t, err)
+
+	otherOrder, err := spec.Parse("used_at")
+	require.NoError(t, err)
+
+	for name, f := range map[string]func() error{
+		"tampered": func() error {
+			_, err := codec.Decode(strings.Replace(token, token[:4], "eyJ3", 1), order)
+			return err
+		},
+		"other secret": func() error {
+			_, err := other.Decode(token, order)
+			return err
+		},
+		"other sort": func() error {
+			_, err := codec.Decode(token, otherOrder)
+			return err
+		},
+		"garbage": func() error {
+			_, err := codec.Decode("abc", order)
+			return err
+		},
+	} {
+		assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(f()).Code, name)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	order, err := spec.Parse("used_at,-created_at")
+	require.NoError(t, err)
+
+	sql := func(keys []any) (string, []any) {
+		var rows []struct{ ID int }
+		stmt := db.Table("api_keys").Where(after(db, order, keys)).Find(&rows).Statement
+
+		return stmt.SQL.String(), stmt.Vars
+	}
+
+	q, vars := sql([]any{"t1", "t2", "7"})
+	assert.Equal(t, `SELECT * FROM "api_keys" WHERE ((("last_used_at" > $1 OR "last_used_at" IS NULL)) OR `+
+		`("last_used_at" = $2 AND "created_at" < $3) OR `+
+		`("last_used_at" = $4 AND "created_at" = $5 AND "id" < $6))`, q)
+	assert.Equal(t, []any{"t1", "t1", "t2", "t1", "t2", "7"}, vars)
+
+	q, vars = sql([]any{nil, "t2", "7"})
+	assert.Equal(t, `SELECT * FROM "api_keys" WHERE (("last_used_at" IS NULL AND "created_at" < $1) OR `+
+		`("last_used_at" IS NULL AND "created_at" = $2 AND "id" < $3))`, q, "nothing after NULL in ASC")
+	assert.Equal(t, []any{"t2", "t2", "7"}, vars)
+}
@@ -0,0 +1,17 @@
+// Package searchdoc - documents of search index built by models, so tables don't depend on search services.
+package searchdoc
+
+type (
+	// Document - document of index. Routing - shard routing, tenant id for tenant scoped indexes.
+	Document struct {
+		Index   string // without prefix.
+		ID      string
+		Routing string
+		Body    any
+	}
+
+	// Indexable - model which is indexed, @see services/search.Plugin.
+	Indexable interface {
+		SearchDocument() Document
+	}
+)
@@ -0,0 +1,52 @@
+package sorting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+)
+
+var spec = NewSpec(map[string]string{
+	"created_at": "created_at",
+	"name":       "name",
+	"used_at":    "last_used_at",
+}, "id", "-created_at")
+
+func TestSpec_Parse(t *testing.T) {
+	s, err := spec.Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, Sort{
+		{Field: "created_at", Column: "created_at", Desc: true},
+		{Column: "id", Desc: true},
+	}, s, "default with tie breaker")
+
+	s, err = spec.Parse(" -used_at, +name ")
+	require.NoError(t, err)
+	assert.Equal(t, Sort{
+		{Field: "used_at", Column: "last_used_at", Desc: true},
+		{Field: "name", Column: "name"},
+		{Column: "id"},
+	}, s)
+	assert.Equal(t, "-used_at,name", s.String())
+
+	for _, expr := range []string{"password", "-last_used_at", "name,-name", "a,b,c,d,e,f,g,h,i"} {
+		_, err = spec.Parse(expr)
+		assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code, expr)
+	}
+}
+
+func TestSort_Apply(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	s, err := spec.Parse("-used_at,name")
+	require.NoError(t, err)
+
+	var rows []struct{ ID int }
+	stmt := s.Apply(db.Table("api_keys")).Find(&rows).Statement
+
+	assert.Equal(t, `SELECT * FROM "api_keys" ORDER BY "last_used_at" DESC,"name","id"`, stmt.SQL.String())
+}
@@ -0,0 +1,87 @@
+package tables
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/fulltext"
+	"github.com/imperiuse/go-app-skeleton/internal/database/searchdoc"
+)
+
+// APIKeyIndex - index of api keys in search cluster, documents are routed by tenant.
+const APIKeyIndex = "api_keys"
+
+// APIKeySearch - full-text index of `api_keys`: by name and prefix.
+var APIKeySearch = fulltext.Index{
+	Table:  "api_keys",
+	Fields: []fulltext.Field{{Column: "name", Weight: 'A'}, {Column: "prefix", Weight: 'B'}},
+}
+
+// APIKeyIndexMapping - mapping of APIKeyIndex (body of create index API).
+var APIKeyIndexMapping = map[string]any{
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"id":           map[string]any{"type": "long"},
+			"tenant_id":    map[string]any{"type": "keyword"},
+			"name":         map[string]any{"type": "text", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+			"prefix":       map[string]any{"type": "keyword"},
+			"created_at":   map[string]any{"type": "date"},
+			"expired_at":   map[string]any{"type": "date"},
+			"last_used_at": map[string]any{"type": "date"},
+			"revoked_at":   map[string]any{"type": "date"},
+		},
+	},
+}
+
+// APIKey - DTO of `api_keys` table. Only sha256 hash of secret part is stored, prefix is visible for humans.
+type APIKey struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	TenantID  uuid.UUID `gorm:"column:tenant_id"`
+	CreatedBy int       `gorm:"column:created_by"` // user id, 0 if created by other api key.
+	Name      string    `gorm:"column:name"`
+
+	Prefix  string `gorm:"column:prefix"`
+	HSecret string `gorm:"column:h_secret"`
+	Rights  int16  `gorm:"column:rights"` // scopes, bitmask of rbac permissions.
+
+	ExpiredAt  *time.Time `gorm:"column:expired_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName - table name.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// TenantScoped - implements tenancy.Scoped.
+func (APIKey) TenantScoped() {}
+
+// SearchDocument - document of search index, without secrets and rights.
+func (k *APIKey) SearchDocument() searchdoc.Document {
+	if k.ID == 0 {
+		return searchdoc.Document{}
+	}
+
+	return searchdoc.Document{
+		Index:   APIKeyIndex,
+		ID:      strconv.Itoa(k.ID),
+		Routing: k.TenantID.String(),
+		Body: map[string]any{
+			"id":           k.ID,
+			"tenant_id":    k.TenantID,
+			"name":         k.Name,
+			"prefix":       k.Prefix,
+			"created_at":   k.CreatedAt,
+			"expired_at":   k.ExpiredAt,
+			"last_used_at": k.LastUsedAt,
+			"revoked_at":   k.RevokedAt,
+		},
+	}
+}
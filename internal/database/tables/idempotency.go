@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey - DTO of `idempotency_keys` table: fingerprint of request with `Idempotency-Key` header
+// and its response for replay. StatusCode is 0 while request is in flight.
+type IdempotencyKey struct {
+	Scope       string      `gorm:"column:scope;primaryKey"` // tenant and client (user or api key) of request.
+	Key         string      `gorm:"column:key;primaryKey"`
+	Fingerprint string      `gorm:"column:fingerprint"` // hash of method, path, query and body.
+	StatusCode  int         `gorm:"column:status_code"`
+	Headers     http.Header `gorm:"column:headers;serializer:json"`
+	Body        []byte      `gorm:"column:body"`
+	LockedAt    time.Time   `gorm:"column:locked_at"`
+	CreatedAt   time.Time   `gorm:"column:created_at"`
+	ExpiredAt   time.Time   `gorm:"column:expired_at"`
+}
+
+// TableName - table name.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,33 @@
+package tables
+
+import "time"
+
+// Role - DTO of `roles` table. Rights - bitmask of permissions, @see services/rbac.
+type Role struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	Name   string `gorm:"column:name"`
+	Rights int16  `gorm:"column:rights"`
+}
+
+// TableName - table name.
+func (Role) TableName() string {
+	return "roles"
+}
+
+// UserRole - DTO of `users_roles` table.
+type UserRole struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	UserID int `gorm:"column:user_id"`
+	RoleID int `gorm:"column:role_id"`
+}
+
+// TableName - table name.
+func (UserRole) TableName() string {
+	return "users_roles"
+}
@@ -0,0 +1,22 @@
+package tables
+
+import "time"
+
+// Session - DTO of `sessions` table (split-token pattern: identifier for lookup + hash of verifier).
+type Session struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	ExpiredAt time.Time `gorm:"column:expired_at"`
+
+	UserID    int    `gorm:"column:user_id"`
+	IP        string `gorm:"column:ip"`
+	UserAgent string `gorm:"column:user_agent"`
+
+	Identifier string `gorm:"column:identifier"`
+	HVerifier  string `gorm:"column:h_verifier"`
+}
+
+// TableName - table name.
+func (Session) TableName() string {
+	return "sessions"
+}
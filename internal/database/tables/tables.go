@@ -1,3 +1,9 @@
 package tables
 
 var AllDTOs = [...]any{}
+
+// TenantScoped - tenant scoped tables, @see tenancy.CheckRLS.
+var TenantScoped = [...]any{&APIKey{}, &UsageCounter{}}
+
+// SearchIndexMappings - indexes of search cluster by name (without prefix), @see search.Client.EnsureIndex.
+var SearchIndexMappings = map[string]any{APIKeyIndex: APIKeyIndexMapping}
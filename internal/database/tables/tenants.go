@@ -0,0 +1,72 @@
+package tables
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of tenant.
+const (
+	TenantActive    = "active"
+	TenantSuspended = "suspended" // requests of tenant are rejected, tenant can be resumed.
+	TenantDeleted   = "deleted"   // soft deleted, requests of tenant are rejected forever.
+)
+
+// Tenant - DTO of `tenants` table.
+type Tenant struct {
+	ID        uuid.UUID `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+	Version   int64     `gorm:"column:version"` // incremented by each change, ETag of tenant.
+
+	Name     string         `gorm:"column:name"`
+	Status   string         `gorm:"column:status"`
+	Settings TenantSettings `gorm:"column:settings"`
+
+	SuspendedAt *time.Time `gorm:"column:suspended_at"`
+	DeletedAt   *time.Time `gorm:"column:deleted_at"`
+}
+
+// TenantSettings - per-tenant settings, stored as jsonb (implements sql.Scanner, driver.Valuer).
+// Zero values mean service defaults.
+type TenantSettings struct {
+	TimeZone string           `json:"time_zone,omitempty"` // IANA time zone, e.g. `Europe/Berlin`.
+	Locale   string           `json:"locale,omitempty"`    // BCP 47 language tag, e.g. `en-US`.
+	Features map[string]bool  `json:"features,omitempty"`  // feature toggles by name.
+	Limits   map[string]int64 `json:"limits,omitempty"`    // limits by name, e.g. `api_keys`.
+}
+
+// TableName - table name.
+func (Tenant) TableName() string {
+	return "tenants"
+}
+
+// IsActive - requests of tenant are allowed.
+func (t *Tenant) IsActive() bool {
+	return t.Status == TenantActive
+}
+
+// Value - implements driver.Valuer.
+func (s TenantSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan - implements sql.Scanner.
+func (s *TenantSettings) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil:
+		*s = TenantSettings{}
+
+		return nil
+	default:
+		return fmt.Errorf("tenant settings: unsupported type %T", src)
+	}
+}
@@ -0,0 +1,25 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageCounter - DTO of `usage_counters` table: usage of metric by tenant aggregated by hour or day.
+type UsageCounter struct {
+	TenantID  uuid.UUID `gorm:"column:tenant_id;primaryKey"`
+	Metric    string    `gorm:"column:metric;primaryKey"`
+	Period    string    `gorm:"column:period;primaryKey"` // hour, day.
+	Bucket    time.Time `gorm:"column:bucket;primaryKey"` // start of period, UTC.
+	Value     int64     `gorm:"column:value"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// TableName - table name.
+func (UsageCounter) TableName() string {
+	return "usage_counters"
+}
+
+// TenantScoped - implements tenancy.Scoped.
+func (UsageCounter) TenantScoped() {}
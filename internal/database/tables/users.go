@@ -0,0 +1,22 @@
+package tables
+
+import "time"
+
+// User - DTO of `users` table.
+type User struct {
+	ID        int       `gorm:"column:id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	Name         string `gorm:"column:name"`
+	Email        string `gorm:"column:email"`
+	Password     string `gorm:"column:password"` // bcrypt hash.
+	PswdHelpHint string `gorm:"column:pswd_help_hint"`
+	AvaURL       string `gorm:"column:ava_url"`
+	Description  string `gorm:"column:description"`
+}
+
+// TableName - table name.
+func (User) TableName() string {
+	return "users"
+}
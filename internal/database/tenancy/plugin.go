@@ -0,0 +1,231 @@
+package tenancy
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	pluginName       = "tenancy"
+	startedTxKey     = "tenancy:started_transaction"
+	setTenantSQL     = "SELECT set_config('app.tenant_id', $1, true), set_config('app.bypass_rls', $2, true)"
+	bypassRLSEnabled = "on"
+)
+
+type (
+	// Config - settings of Plugin.
+	Config struct {
+		// RLS - set `app.tenant_id` (and `app.bypass_rls` for elevated context) for each statement by SET LOCAL,
+		// statements out of transaction are wrapped into transaction. Rows are read after callbacks, so `Row`,
+		// `Rows` and `Raw().Scan` of tenant (or elevated) context out of transaction fail with ErrRowsOutOfTx:
+		// run them in `Transaction`. @see CheckRLS.
+		RLS bool
+	}
+
+	// Plugin - gorm plugin of tenant isolation. Queries, updates and deletes of Scoped models get
+	// `tenant_id = <tenant of context>` condition, inserted models get tenant of context. Statements without
+	// tenant in context fail with ErrNoTenant unless context is elevated. NB! Raw SQL and `Table()` without model
+	// aren't scoped (only by RLS).
+	Plugin struct {
+		cfg    Config
+		scoped sync.Map // *schema.Schema -> bool.
+	}
+)
+
+// NewPlugin - constructor of Plugin, register it by db.Use.
+func NewPlugin(cfg Config) *Plugin {
+	return &Plugin{cfg: cfg}
+}
+
+// Name - implements gorm.Plugin.
+func (p *Plugin) Name() string {
+	return pluginName
+}
+
+// Initialize - implements gorm.Plugin.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	errs := []error{
+		cb.Query().Before("gorm:query").Register(pluginName+":scope", p.scope),
+		cb.Row().Before("gorm:row").Register(pluginName+":scope", p.scope),
+		cb.Update().Before("gorm:update").Register(pluginName+":scope", p.scope),
+		cb.Delete().Before("gorm:delete").Register(pluginName+":scope", p.scope),
+		cb.Create().Before("gorm:create").Register(pluginName+":assign", p.assign),
+	}
+
+	if p.cfg.RLS {
+		errs = append(errs,
+			cb.Query().Before("*").Register(pluginName+":begin", p.begin),
+			cb.Query().After("*").Register(pluginName+":commit", p.commit),
+			cb.Create().Before("*").Register(pluginName+":begin", p.begin),
+			cb.Create().After("*").Register(pluginName+":commit", p.commit),
+			cb.Update().Before("*").Register(pluginName+":begin", p.begin),
+			cb.Update().After("*").Register(pluginName+":commit", p.commit),
+			cb.Delete().Before("*").Register(pluginName+":begin", p.begin),
+			cb.Delete().After("*").Register(pluginName+":commit", p.commit),
+			cb.Raw().Before("*").Register(pluginName+":begin", p.begin),
+			cb.Raw().After("*").Register(pluginName+":commit", p.commit),
+			cb.Row().Before("*").Register(pluginName+":set", p.set),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// scope - add tenant condition to statement of Scoped model.
+func (p *Plugin) scope(db *gorm.DB) {
+	if db.Error != nil || !p.isScoped(db.Statement.Schema) || IsElevated(db.Statement.Context) {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: Column}, Value: tenantID},
+	}})
+}
+
+// assign - set tenant of context to inserted Scoped models (without tenant), other tenant is forbidden.
+func (p *Plugin) assign(db *gorm.DB) {
+	if db.Error != nil || !p.isScoped(db.Statement.Schema) || IsElevated(db.Statement.Context) {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+
+		return
+	}
+
+	f := db.Statement.Schema.LookUpField(Column)
+	if f == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	set := func(rv reflect.Value) {
+		v, zero := f.ValueOf(ctx, rv)
+		if zero {
+			_ = db.AddError(f.Set(ctx, rv, tenantID))
+		} else if v != tenantID {
+			_ = db.AddError(ErrTenantMismatch)
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			set(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		set(rv)
+	default:
+	}
+}
+
+// begin - start transaction (if statement isn't in transaction) and set tenant settings of RLS policies.
+func (p *Plugin) begin(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	elevated := IsElevated(db.Statement.Context)
+
+	if !ok && !elevated {
+		return // not tenant request: RLS policies deny access to scoped tables.
+	}
+
+	if tx := db.Begin(); tx.Error == nil {
+		db.Statement.ConnPool = tx.Statement.ConnPool
+		db.InstanceSet(startedTxKey, true)
+	} else if !errors.Is(tx.Error, gorm.ErrInvalidTransaction) { // ErrInvalidTransaction - already in transaction.
+		_ = db.AddError(tx.Error)
+
+		return
+	}
+
+	setTenant(db, tenantID, elevated)
+}
+
+// set - set tenant settings of RLS policies for rows statement, which must be in transaction: it can't be committed
+// by callback, rows are read after callbacks.
+func (p *Plugin) set(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	tenantID, ok := FromContext(db.Statement.Context)
+	elevated := IsElevated(db.Statement.Context)
+
+	if !ok && !elevated {
+		return
+	}
+
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+		_ = db.AddError(ErrRowsOutOfTx)
+
+		return
+	}
+
+	setTenant(db, tenantID, elevated)
+}
+
+// commit - finish transaction started by begin.
+func (p *Plugin) commit(db *gorm.DB) {
+	if _, ok := db.InstanceGet(startedTxKey); !ok {
+		return
+	}
+
+	if db.Error != nil {
+		db.Rollback()
+	} else {
+		db.Commit()
+	}
+
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// setTenant - SET LOCAL tenant settings of RLS policies in transaction of statement.
+func setTenant(db *gorm.DB, tenantID uuid.UUID, elevated bool) {
+	var tenant, bypass string
+	if tenantID != uuid.Nil {
+		tenant = tenantID.String()
+	}
+
+	if elevated {
+		bypass = bypassRLSEnabled
+	}
+
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, setTenantSQL, tenant, bypass); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+func (p *Plugin) isScoped(s *schema.Schema) bool {
+	if s == nil {
+		return false
+	}
+
+	if v, ok := p.scoped.Load(s); ok {
+		return v.(bool)
+	}
+
+	_, scoped := reflect.New(s.ModelType).Interface().(Scoped)
+	p.scoped.Store(s, scoped)
+
+	return scoped
+}
@@ -0,0 +1,73 @@
+package tenancy
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// policyName - name of RLS policy of tenant scoped tables.
+	policyName = "tenant_isolation"
+
+	// checkRLSSQL - RLS is enabled and forced (applied to owner of table too), NULL if table doesn't exist.
+	checkRLSSQL = "SELECT relrowsecurity AND relforcerowsecurity FROM pg_class WHERE oid = to_regclass(?)"
+)
+
+var ErrRLSMismatch = errors.New("tenancy rls: row level security of table doesn't match config")
+
+// CheckRLS - check that row level security of tenant scoped tables is in state expected by config (Plugin with
+// Config.RLS sets `app.tenant_id` and `app.bypass_rls` used by policy). RLS is enabled by SQL migration
+// (migrations/migrations, statements are RLSStatements), it's only checked on start, nothing is changed.
+func CheckRLS(db *gorm.DB, enabled bool, models ...any) error {
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("tenancy rls: parse model %T: %w", model, err)
+		}
+
+		if _, ok := model.(Scoped); !ok {
+			return fmt.Errorf("tenancy rls: model %T isn't tenant scoped", model)
+		}
+
+		var forced bool
+
+		res := db.Raw(checkRLSSQL, stmt.Schema.Table).Find(&forced)
+		if res.Error != nil {
+			return fmt.Errorf("tenancy rls of %s: %w", stmt.Schema.Table, res.Error)
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("tenancy rls: table %s not found", stmt.Schema.Table)
+		}
+
+		if forced != enabled {
+			return fmt.Errorf("%w: table %s has rls=%t, config has rls=%t (apply or roll back rls migration)",
+				ErrRLSMismatch, stmt.Schema.Table, forced, enabled)
+		}
+	}
+
+	return nil
+}
+
+// RLSStatements - DDL which enables (or disables) row level security of table.
+func RLSStatements(table string, enabled bool) []string {
+	if !enabled {
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s NO FORCE ROW LEVEL SECURITY", table),
+			fmt.Sprintf("ALTER TABLE %s DISABLE ROW LEVEL SECURITY", table),
+			fmt.Sprintf("DROP POLICY IF EXISTS %s ON %s", policyName, table),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("DROP POLICY IF EXISTS %s ON %s", policyName, table),
+		fmt.Sprintf(`CREATE POLICY %s ON %s USING (
+    current_setting('app.bypass_rls', true) = 'on'
+    OR %s = NULLIF(current_setting('app.tenant_id', true), '')::uuid
+)`, policyName, table, Column),
+		fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", table),
+		fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", table),
+	}
+}
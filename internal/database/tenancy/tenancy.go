@@ -0,0 +1,54 @@
+// Package tenancy - isolation of tenants data: gorm plugin which scopes queries of tenant scoped models by tenant of
+// context and (optionally) postgres row level security.
+package tenancy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Column - tenant column of tenant scoped tables.
+const Column = "tenant_id"
+
+var (
+	ErrNoTenant       = errors.New("tenancy: query of tenant scoped model without tenant in context")
+	ErrTenantMismatch = errors.New("tenancy: model belongs to other tenant than tenant of context")
+	ErrRowsOutOfTx    = errors.New("tenancy: rows of tenant (or elevated) context are read only in transaction with rls")
+)
+
+// Scoped - model of tenant scoped table (with Column), its queries are always filtered by tenant, @see Plugin.
+type Scoped interface {
+	TenantScoped()
+}
+
+type (
+	tenantKey   struct{}
+	elevatedKey struct{}
+)
+
+// WithTenant - context of tenant, queries with it see only rows of tenant.
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext - tenant of context.
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+
+	return tenantID, ok && tenantID != uuid.Nil
+}
+
+// Elevate - context of queries across all tenants (system jobs, lookup of api key by prefix on auth, etc.).
+// Use it explicitly and as narrow as possible, tenant of context (if any) is ignored.
+func Elevate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, elevatedKey{}, true)
+}
+
+// IsElevated - context is elevated, @see Elevate.
+func IsElevated(ctx context.Context) bool {
+	elevated, _ := ctx.Value(elevatedKey{}).(bool)
+
+	return elevated
+}
@@ -0,0 +1,140 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+)
+
+type (
+	item struct {
+		ID       int       `gorm:"column:id;primaryKey"`
+		TenantID uuid.UUID `gorm:"column:tenant_id"`
+		Name     string    `gorm:"column:name"`
+	}
+
+	global struct {
+		ID int `gorm:"column:id;primaryKey"`
+	}
+)
+
+func (item) TenantScoped() {}
+
+func newDryRunDB(t *testing.T, cfg Config) *gorm.DB {
+	db := dbtest.DryRun(t)
+	require.NoError(t, db.Use(NewPlugin(cfg)))
+
+	return db
+}
+
+func TestPlugin_Scope(t *testing.T) {
+	db := newDryRunDB(t, Config{})
+	tenantID := uuid.New()
+	ctx := WithTenant(context.Background(), tenantID)
+
+	var items []item
+
+	err := db.WithContext(context.Background()).Find(&items).Error
+	require.ErrorIs(t, err, ErrNoTenant)
+
+	res := db.WithContext(ctx).Where("name = ?", "a").Find(&items)
+	require.NoError(t, res.Error)
+	assert.Equal(t, `SELECT * FROM "items" WHERE name = $1 AND "items"."tenant_id" = $2`, res.Statement.SQL.String())
+	assert.Equal(t, []any{"a", tenantID}, res.Statement.Vars)
+
+	res = db.WithContext(ctx).Model(&item{ID: 1}).Update("name", "b")
+	require.NoError(t, res.Error)
+	assert.Equal(t, `UPDATE "items" SET "name"=$1 WHERE "items"."tenant_id" = $2 AND "id" = $3`,
+		res.Statement.SQL.String())
+
+	res = db.WithContext(ctx).Delete(&item{ID: 1})
+	require.NoError(t, res.Error)
+	assert.Equal(t, `DELETE FROM "items" WHERE "items"."tenant_id" = $1 AND "items"."id" = $2`,
+		res.Statement.SQL.String())
+
+	res = db.WithContext(Elevate(context.Background())).Find(&items)
+	require.NoError(t, res.Error)
+	assert.Equal(t, `SELECT * FROM "items"`, res.Statement.SQL.String())
+
+	res = db.WithContext(context.Background()).Find(&[]global{})
+	require.NoError(t, res.Error)
+	assert.Equal(t, `SELECT * FROM "globals"`, res.Statement.SQL.String())
+}
+
+func TestPlugin_Assign(t *testing.T) {
+	db := newDryRunDB(t, Config{})
+	tenantID := uuid.New()
+	ctx := WithTenant(context.Background(), tenantID)
+
+	items := []item{{Name: "a"}, {Name: "b", TenantID: tenantID}}
+	require.NoError(t, db.WithContext(ctx).Create(&items).Error)
+	assert.Equal(t, tenantID, items[0].TenantID)
+	assert.Equal(t, tenantID, items[1].TenantID)
+
+	err := db.WithContext(ctx).Create(&item{Name: "c", TenantID: uuid.New()}).Error
+	require.ErrorIs(t, err, ErrTenantMismatch)
+
+	err = db.WithContext(context.Background()).Create(&item{Name: "d", TenantID: tenantID}).Error
+	require.ErrorIs(t, err, ErrNoTenant)
+
+	other := item{Name: "e", TenantID: uuid.New()}
+	require.NoError(t, db.WithContext(Elevate(context.Background())).Create(&other).Error)
+}
+
+func TestPlugin_RowsOutOfTx(t *testing.T) {
+	db := newDryRunDB(t, Config{RLS: true})
+
+	var v []int
+
+	err := db.WithContext(WithTenant(context.Background(), uuid.New())).Raw("SELECT 1").Scan(&v).Error
+	require.ErrorIs(t, err, ErrRowsOutOfTx, "rows would be read without tenant settings")
+
+	err = db.WithContext(Elevate(context.Background())).Raw("SELECT 1").Scan(&v).Error
+	require.ErrorIs(t, err, ErrRowsOutOfTx)
+
+	err = db.WithContext(context.Background()).Raw("SELECT 1").Scan(&v).Error
+	require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported, "rows without tenant are read as is")
+}
+
+func TestRLSStatements(t *testing.T) {
+	enable := RLSStatements("items", true)
+	require.Len(t, enable, 4)
+	assert.Contains(t, enable[1], "CREATE POLICY tenant_isolation ON items")
+	assert.Contains(t, enable[1], "tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid")
+	assert.Equal(t, "ALTER TABLE items FORCE ROW LEVEL SECURITY", enable[3])
+
+	assert.Equal(t, []string{
+		"ALTER TABLE items NO FORCE ROW LEVEL SECURITY",
+		"ALTER TABLE items DISABLE ROW LEVEL SECURITY",
+		"DROP POLICY IF EXISTS tenant_isolation ON items",
+	}, RLSStatements("items", false))
+}
+
+func TestCheckRLS(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	forced := map[string]bool{"items": true}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:rls", func(tx *gorm.DB) {
+		v, ok := forced[tx.Statement.Vars[0].(string)] //nolint:forcetypeassert // table name.
+		if dest, isBool := tx.Statement.Dest.(*bool); isBool && ok {
+			*dest, tx.RowsAffected = v, 1
+		}
+	}))
+
+	require.NoError(t, CheckRLS(db, true, &item{}))
+	require.ErrorIs(t, CheckRLS(db, false, &item{}), ErrRLSMismatch)
+
+	forced["items"] = false
+	require.NoError(t, CheckRLS(db, false, &item{}))
+
+	delete(forced, "items")
+	require.ErrorContains(t, CheckRLS(db, false, &item{}), "table items not found")
+
+	require.ErrorContains(t, CheckRLS(db, true, &global{}), "isn't tenant scoped")
+}
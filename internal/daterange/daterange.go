@@ -0,0 +1,281 @@
+// Package daterange - parse `from_date`/`to_date` query params of search endpoints.
+//
+// Accepted bound values:
+//   - RFC3339 time: `2024-01-02T15:04:05Z`, `2024-01-02T15:04:05+03:00`;
+//   - local time and date in `tz`: `2024-01-02T15:04:05`, `2024-01-02` (date `to` includes whole day);
+//   - unix timestamp in seconds or milliseconds: `1704067200`;
+//   - relative expression: `now`, `now-7d`, `now+1h`, `now/d`, `now-1M/M` (units: s m h d w M y, `/` - round);
+//   - named range: today, yesterday, this_week, last_week, this_month, last_month, this_year, last_year
+//     (in `from_date` without `to_date` it means whole range).
+//
+// Range is half-open: From <= t < To.
+package daterange
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// embedded tz database, `tz` must work in scratch/distroless containers too.
+	_ "time/tzdata"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+)
+
+// Names of query params.
+const (
+	FromParam = "from_date"
+	ToParam   = "to_date"
+	TZParam   = "tz"
+)
+
+const (
+	localDateTime = "2006-01-02T15:04:05"
+
+	// unixMillisThreshold - bigger unix timestamps are in milliseconds (it's year 33658 in seconds).
+	unixMillisThreshold = 1e12
+)
+
+var relativeRegexp = regexp.MustCompile(`^now(?:([+-])(\d{1,6})([smhdwMy]))?(?:/([smhdwMy]))?$`)
+
+type (
+	// Config - settings of Parser.
+	Config struct {
+		// MaxRange - max To - From, 0 - unlimited. When set, open bounds are clamped: To = now, From = To - MaxRange.
+		MaxRange time.Duration
+		// Location - default time zone for local times and dates, if `tz` param is empty. Nil - UTC.
+		Location *time.Location
+	}
+
+	// Parser - date range parser of endpoint.
+	Parser struct {
+		cfg Config
+		now func() time.Time
+	}
+
+	// Range - parsed date range, zero bound - open.
+	Range struct {
+		From time.Time
+		To   time.Time
+	}
+
+	// bound - which range bound is parsed, `to` is rounded up.
+	bound int
+)
+
+const (
+	lower bound = iota
+	upper
+)
+
+// New - constructor of Parser.
+func New(cfg Config) *Parser {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	return &Parser{cfg: cfg, now: time.Now}
+}
+
+// Parse - parse range bounds in time zone tz (IANA name, empty - default). Errors are 400 (bad query param) apperror.
+func (p *Parser) Parse(from, to, tz string) (Range, error) {
+	var r Range
+
+	loc := p.cfg.Location
+	if tz = strings.TrimSpace(tz); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return r, apperror.BadQueryParam(TZParam, fmt.Sprintf("unknown time zone `%s`", tz))
+		}
+	}
+
+	now := p.now().In(loc)
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+
+	if from != "" {
+		var err error
+		if r.From, err = parse(from, lower, now); err != nil {
+			return r, apperror.BadQueryParam(FromParam, err.Error())
+		}
+
+		if to == "" {
+			if start, end, ok := named(from, now); ok {
+				r.From, r.To = start, end
+			}
+		}
+	}
+
+	if to != "" {
+		var err error
+		if r.To, err = parse(to, upper, now); err != nil {
+			return r, apperror.BadQueryParam(ToParam, err.Error())
+		}
+	}
+
+	if p.cfg.MaxRange > 0 {
+		if r.To.IsZero() {
+			r.To = now
+		}
+
+		if r.From.IsZero() {
+			r.From = r.To.Add(-p.cfg.MaxRange)
+		}
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() {
+		if r.To.Before(r.From) {
+			return r, apperror.BadQueryParam(ToParam, "`to_date` must not be before `from_date`")
+		}
+
+		if p.cfg.MaxRange > 0 && r.To.Sub(r.From) > p.cfg.MaxRange {
+			return r, apperror.BadQueryParam(ToParam, fmt.Sprintf("date range must not exceed %s", p.cfg.MaxRange))
+		}
+	}
+
+	return r, nil
+}
+
+// IsZero - both bounds are open.
+func (r Range) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Contains - From <= t < To, open bounds are ignored.
+func (r Range) Contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || t.Before(r.To))
+}
+
+func parse(s string, b bound, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(localDateTime, s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		if b == upper {
+			t = t.AddDate(0, 0, 1) // whole day.
+		}
+
+		return t, nil
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+		if n >= unixMillisThreshold {
+			return time.UnixMilli(n).In(now.Location()), nil
+		}
+
+		return time.Unix(n, 0).In(now.Location()), nil
+	}
+
+	if m := relativeRegexp.FindStringSubmatch(s); m != nil {
+		t := now
+
+		if m[1] != "" {
+			n, _ := strconv.Atoi(m[2])
+			if m[1] == "-" {
+				n = -n
+			}
+
+			t = shift(t, n, m[3])
+		}
+
+		if m[4] != "" {
+			t = truncate(t, m[4])
+			if b == upper {
+				t = shift(t, 1, m[4])
+			}
+		}
+
+		return t, nil
+	}
+
+	if start, end, ok := named(s, now); ok {
+		if b == upper {
+			return end, nil
+		}
+
+		return start, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date `%s`, expected RFC3339, date, unix timestamp, "+
+		"relative (`now-7d`, `now/d`) or named range (`today`, `last_month`)", s)
+}
+
+// named - bounds of named range.
+func named(s string, now time.Time) (time.Time, time.Time, bool) {
+	var (
+		start time.Time
+		unit  string
+	)
+
+	switch s {
+	case "today":
+		start, unit = truncate(now, "d"), "d"
+	case "yesterday":
+		start, unit = shift(truncate(now, "d"), -1, "d"), "d"
+	case "this_week":
+		start, unit = truncate(now, "w"), "w"
+	case "last_week":
+		start, unit = shift(truncate(now, "w"), -1, "w"), "w"
+	case "this_month":
+		start, unit = truncate(now, "M"), "M"
+	case "last_month":
+		start, unit = shift(truncate(now, "M"), -1, "M"), "M"
+	case "this_year":
+		start, unit = truncate(now, "y"), "y"
+	case "last_year":
+		start, unit = shift(truncate(now, "y"), -1, "y"), "y"
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, shift(start, 1, unit), true
+}
+
+// shift - add n units, calendar units (d w M y) respect time zone (DST).
+func shift(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n) //nolint:gomnd // days in week.
+	case "M":
+		return t.AddDate(0, n, 0)
+	default: // y.
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// truncate - start of unit in time zone of t, week starts on Monday.
+func truncate(t time.Time, unit string) time.Time {
+	y, mon, d := t.Date()
+	loc := t.Location()
+
+	switch unit {
+	case "s":
+		return t.Truncate(time.Second)
+	case "m":
+		return time.Date(y, mon, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case "h":
+		return time.Date(y, mon, d, t.Hour(), 0, 0, 0, loc)
+	case "d":
+		return time.Date(y, mon, d, 0, 0, 0, 0, loc)
+	case "w":
+		return time.Date(y, mon, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc) //nolint:gomnd // Monday is first.
+	case "M":
+		return time.Date(y, mon, 1, 0, 0, 0, 0, loc)
+	default: // y.
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
@@ -0,0 +1,72 @@
+package daterange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+)
+
+func TestParser_Parse(t *testing.T) {
+	p := New(Config{})
+	p.now = func() time.Time { return time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC) } // Thursday.
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	utc := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, time.UTC) }
+
+	for _, tc := range []struct {
+		from, to, tz string
+		expected     Range
+	}{
+		{"", "", "", Range{}},
+		{"2024-01-01T10:00:00+03:00", "", "", Range{From: utc(2024, 1, 1, 7)}},
+		{"2024-01-01", "2024-01-31", "", Range{From: utc(2024, 1, 1, 0), To: utc(2024, 2, 1, 0)}},
+		{"2024-01-01", "", "Asia/Tokyo", Range{From: time.Date(2024, 1, 1, 0, 0, 0, 0, tokyo)}},
+		{"1704067200", "1704153600000", "", Range{From: utc(2024, 1, 1, 0), To: utc(2024, 1, 2, 0)}},
+		{"now-7d/d", "now/d", "", Range{From: utc(2024, 3, 7, 0), To: utc(2024, 3, 15, 0)}},
+		{"now-1h", "now", "", Range{From: time.Date(2024, 3, 14, 14, 9, 26, 0, time.UTC), To: p.now()}},
+		{"today", "", "", Range{From: utc(2024, 3, 14, 0), To: utc(2024, 3, 15, 0)}},
+		{"last_month", "", "", Range{From: utc(2024, 2, 1, 0), To: utc(2024, 3, 1, 0)}},
+		{"this_week", "", "", Range{From: utc(2024, 3, 11, 0), To: utc(2024, 3, 18, 0)}},
+		{"last_year", "yesterday", "", Range{From: utc(2023, 1, 1, 0), To: utc(2024, 3, 14, 0)}},
+		{"today", "", "Asia/Tokyo", Range{ // it's already 15th in Tokyo.
+			From: time.Date(2024, 3, 15, 0, 0, 0, 0, tokyo), To: time.Date(2024, 3, 16, 0, 0, 0, 0, tokyo)}},
+	} {
+		r, err := p.Parse(tc.from, tc.to, tc.tz)
+		require.NoError(t, err, tc)
+		assert.True(t, tc.expected.From.Equal(r.From), "%v: from %v", tc, r.From)
+		assert.True(t, tc.expected.To.Equal(r.To), "%v: to %v", tc, r.To)
+	}
+
+	for _, tc := range [][3]string{
+		{"yesterday?", "", ""},
+		{"", "now-1x", ""},
+		{"2024-01-02", "2024-01-01T00:00:00Z", ""},
+		{"", "", "Mars/Olympus"},
+	} {
+		_, err = p.Parse(tc[0], tc[1], tc[2])
+		assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code, tc)
+	}
+}
+
+func TestParser_MaxRange(t *testing.T) {
+	p := New(Config{MaxRange: 31 * 24 * time.Hour})
+	p.now = func() time.Time { return time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC) }
+
+	r, err := p.Parse("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, Range{From: time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), To: p.now()}, r, "clamped")
+
+	_, err = p.Parse("last_month", "", "")
+	require.NoError(t, err)
+
+	_, err = p.Parse("this_year", "", "")
+	e := apperror.From(err)
+	assert.Equal(t, apperror.ErrBadQueryParam.Code, e.Code)
+	assert.Equal(t, ToParam, e.Extensions["param"])
+}
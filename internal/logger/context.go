@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type ctxKey struct{}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault - set logger, which is returned by FromContext for contexts without request-scoped logger.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// NewContext - store logger in context.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext - request-scoped logger of context (@see WithContext), or default logger (Nop if not set).
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := fromContext(ctx); ok {
+		return l
+	}
+
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+
+	return zap.NewNop()
+}
+
+// WithContext - add fields to logger of context, returns context with this child logger.
+// All downstream code which logs via FromContext(ctx) logs these fields automatically.
+func WithContext(ctx context.Context, fields ...zapcore.Field) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(fields...))
+}
+
+func fromContext(ctx context.Context) (*Logger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	l, ok := ctx.Value(ctxKey{}).(*Logger)
+
+	return l, ok && l != nil
+}
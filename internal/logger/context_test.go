@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func Test_LoggerContext(t *testing.T) {
+	assert.NotNil(t, FromContext(context.Background()), "nop logger if there's no default")
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := NewContext(context.Background(), zap.New(core).With(zap.String("request_id", "r1")))
+	ctx = WithContext(ctx, zap.Int("user_id", 1))
+
+	FromContext(ctx).Info("handler")
+
+	gl := NewGormLogger(zap.NewNop(), GormLoggerConfig{LogLevel: gormLogger.Info})
+	gl.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	if assert.Equal(t, 2, logs.Len()) {
+		for _, e := range logs.All() {
+			assert.Equal(t, map[string]any{"request_id": "r1", "user_id": int64(1)}, e.ContextMap())
+		}
+	}
+}
@@ -124,3 +124,27 @@ func TraceID(traceID string) zapcore.Field {
 func UserAgent(ua []byte) zapcore.Field {
 	return zap.ByteString("user_agent", ua)
 }
+
+func UserID(id int) zapcore.Field {
+	return zap.Int("user_id", id)
+}
+
+func Audit(event string) zapcore.Field {
+	return zap.String("audit", event)
+}
+
+func RequestID(id string) zapcore.Field {
+	return zap.String("request_id", id)
+}
+
+func Method(method string) zapcore.Field {
+	return zap.String("method", method)
+}
+
+func Route(route string) zapcore.Field {
+	return zap.String("route", route)
+}
+
+func TenantID(tenantID string) zapcore.Field {
+	return zap.String("tenant_id", tenantID)
+}
@@ -9,6 +9,8 @@ import (
 	"go.uber.org/zap"
 	gormLogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/utils"
+
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
 )
 
 type GormLoggerConfig = gormLogger.Config
@@ -49,19 +51,19 @@ func (l *GormLogger) LogMode(lvl gormLogger.LogLevel) gormLogger.Interface {
 	return &newLogger
 }
 
-func (l *GormLogger) Info(_ context.Context, msg string, data ...interface{}) {
-	l.log.Sugar().Infof(infoStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.sugar(ctx).Infof(infoStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
 }
 
-func (l *GormLogger) Warn(_ context.Context, msg string, data ...interface{}) {
-	l.log.Sugar().Warnf(warnStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.sugar(ctx).Warnf(warnStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
 }
 
-func (l *GormLogger) Error(_ context.Context, msg string, data ...interface{}) {
-	l.log.Sugar().Errorf(errStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.sugar(ctx).Errorf(errStr+msg, append([]interface{}{utils.FileWithLineNum()}, data...)...)
 }
 
-func (l *GormLogger) Trace(_ context.Context, begin time.Time, fc func() (string, int64), err error) {
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	if l.cfg.LogLevel <= gormLogger.Silent {
 		return
 	}
@@ -72,24 +74,37 @@ func (l *GormLogger) Trace(_ context.Context, begin time.Time, fc func() (string
 		(!errors.Is(err, gormLogger.ErrRecordNotFound) || !l.cfg.IgnoreRecordNotFoundError):
 		sql, rows := fc()
 		if rows == -1 {
-			l.log.Sugar().Errorf(traceErrStr, utils.FileWithLineNum(), err, float64(elapsed.Nanoseconds())/mgn, "-", sql)
+			l.sugar(ctx).Errorf(traceErrStr, utils.FileWithLineNum(), err, float64(elapsed.Nanoseconds())/mgn, "-", sql)
 		} else {
-			l.log.Sugar().Errorf(traceErrStr, utils.FileWithLineNum(), err, float64(elapsed.Nanoseconds())/mgn, rows, sql)
+			l.sugar(ctx).Errorf(traceErrStr, utils.FileWithLineNum(), err, float64(elapsed.Nanoseconds())/mgn, rows, sql)
 		}
 	case elapsed > l.cfg.SlowThreshold && l.cfg.SlowThreshold != 0 && l.cfg.LogLevel >= gormLogger.Warn:
 		sql, rows := fc()
 		slowLog := fmt.Sprintf("SLOW SQL >= %v", l.cfg.SlowThreshold)
 		if rows == -1 {
-			l.log.Sugar().Warnf(traceWarnStr, utils.FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/mgn, "-", sql)
+			l.sugar(ctx).Warnf(traceWarnStr, utils.FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/mgn, "-", sql)
 		} else {
-			l.log.Sugar().Warnf(traceWarnStr, utils.FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/mgn, rows, sql)
+			l.sugar(ctx).Warnf(traceWarnStr, utils.FileWithLineNum(), slowLog, float64(elapsed.Nanoseconds())/mgn, rows, sql)
 		}
 	case l.cfg.LogLevel == gormLogger.Info:
 		sql, rows := fc()
 		if rows == -1 {
-			l.log.Sugar().Infof(traceStr, utils.FileWithLineNum(), float64(elapsed.Nanoseconds())/mgn, "-", sql)
+			l.sugar(ctx).Infof(traceStr, utils.FileWithLineNum(), float64(elapsed.Nanoseconds())/mgn, "-", sql)
 		} else {
-			l.log.Sugar().Infof(traceStr, utils.FileWithLineNum(), float64(elapsed.Nanoseconds())/mgn, rows, sql)
+			l.sugar(ctx).Infof(traceStr, utils.FileWithLineNum(), float64(elapsed.Nanoseconds())/mgn, rows, sql)
 		}
 	}
 }
+
+// sugar - request-scoped logger of context (if any), so sql logs can be correlated with request.
+func (l *GormLogger) sugar(ctx context.Context) *zap.SugaredLogger {
+	if log, ok := fromContext(ctx); ok {
+		return log.Sugar()
+	}
+
+	if id := requestid.FromContext(ctx); id != "" {
+		return l.log.With(zap.String("request_id", id)).Sugar()
+	}
+
+	return l.log.Sugar()
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -13,11 +15,21 @@ const (
 	status      = "status"
 	destination = "destination"
 	errName     = "error"
+	priority    = "priority"
+	problemType = "type"
+	index       = "index"
+	operation   = "op"
+	tenant      = "tenant"
+	metric      = "metric"
+	period      = "period"
+	route       = "route"
+	result      = "result"
 )
 
 const (
 	Success = "success"
 	Failed  = "failed"
+	Dropped = "dropped"
 )
 
 var (
@@ -46,6 +58,81 @@ var (
 		[]string{status, destination},
 	)
 
+	concurrencyLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "concurrency_limit",
+		Help:      "Current adaptive limit of in-flight HTTP requests",
+	})
+
+	inFlightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "in_flight_requests",
+		Help:      "In-flight HTTP requests (under concurrency limiter)",
+	})
+
+	queuedRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "queued_requests",
+		Help:      "HTTP requests waiting for free slot of concurrency limiter",
+	})
+
+	shedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "shed_requests",
+		Help:      "HTTP requests rejected by load shedding",
+	},
+		[]string{priority},
+	)
+
+	apiErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "api_errors",
+		Help:      "API errors (problem details) count by problem type",
+	},
+		[]string{problemType, status},
+	)
+
+	searchIndexed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "search",
+		Name:      "indexed_documents",
+		Help:      "Documents sent to search cluster by index, operation and status (success, failed, dropped)",
+	},
+		[]string{index, operation, status},
+	)
+
+	usage = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "metering",
+		Name:      "usage_total",
+		Help:      "Usage by tenant and metric, tenant label is bounded (rest tenants are `other`)",
+	},
+		[]string{tenant, metric},
+	)
+
+	quotaExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "metering",
+		Name:      "quota_exceeded",
+		Help:      "Rejections by hard quota by metric and period",
+	},
+		[]string{metric, period},
+	)
+
+	responseCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "response_cache",
+		Help:      "Lookups of response cache by route and result (hit, miss, coalesced, bypass)",
+	},
+		[]string{route, result},
+	)
+
 	kafkaProcessedMsgs = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
 		Subsystem: "kafka",
@@ -67,3 +154,33 @@ func ProcessedReportsInc(status string, destination string) {
 func KafkaProcessedMsgsInc(status string, errName string) {
 	kafkaProcessedMsgs.WithLabelValues(status, errName).Inc()
 }
+
+func SetConcurrency(limit int, inFlight int, queued int) {
+	concurrencyLimit.Set(float64(limit))
+	inFlightRequests.Set(float64(inFlight))
+	queuedRequests.Set(float64(queued))
+}
+
+func ShedRequestsInc(priority string) {
+	shedRequests.WithLabelValues(priority).Inc()
+}
+
+func APIErrorsInc(problemType string, status int) {
+	apiErrors.WithLabelValues(problemType, strconv.Itoa(status)).Inc()
+}
+
+func SearchIndexedInc(index string, op string, status string) {
+	searchIndexed.WithLabelValues(index, op, status).Inc()
+}
+
+func UsageAdd(tenant string, metric string, n float64) {
+	usage.WithLabelValues(tenant, metric).Add(n)
+}
+
+func QuotaExceededInc(metric string, period string) {
+	quotaExceeded.WithLabelValues(metric, period).Inc()
+}
+
+func ResponseCacheInc(route string, result string) {
+	responseCache.WithLabelValues(route, result).Inc()
+}
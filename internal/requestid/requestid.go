@@ -0,0 +1,129 @@
+// Package requestid - request id (correlation id) propagation via context.Context and http headers.
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header - http header with request id.
+	Header = "X-Request-Id"
+	// TraceparentHeader - W3C trace context header (https://www.w3.org/TR/trace-context/#traceparent-header).
+	TraceparentHeader = "traceparent"
+
+	maxLength = 128
+)
+
+type ctxKey struct{}
+
+// WithContext - store request id in context.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext - get request id from context, empty string if absent.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(ctxKey{}).(string)
+
+	return id
+}
+
+// New - generate new request id.
+func New() string {
+	return uuid.NewString()
+}
+
+// FromRequest - take valid upstream request id, or trace-id of valid `traceparent`, or generate new one.
+func FromRequest(r *http.Request) string {
+	if id := r.Header.Get(Header); IsValid(id) {
+		return id
+	}
+
+	if traceID, ok := ParseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+		return traceID
+	}
+
+	return New()
+}
+
+// IsValid - upstream request id is trusted only if it's short and contains only safe symbols (no log injection).
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, r := range id {
+		if !isSafe(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseTraceparent - get trace-id from `traceparent` header: `version-traceid-parentid-flags`,
+// e.g. `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`.
+func ParseTraceparent(header string) (traceID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", false
+	}
+
+	if !isLowerHex(parts[0]) || !isLowerHex(parts[1]) || !isLowerHex(parts[2]) ||
+		parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// Transport - http.RoundTripper which forwards request id of context to outbound calls.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport - wrap base transport (http.DefaultTransport if nil).
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base}
+}
+
+// RoundTrip - implements http.RoundTripper.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := FromContext(r.Context())
+	if id == "" || r.Header.Get(Header) != "" {
+		return t.Base.RoundTrip(r)
+	}
+
+	// RoundTripper must not modify request, @see http.RoundTripper docs.
+	r = r.Clone(r.Context())
+	r.Header.Set(Header, id)
+
+	return t.Base.RoundTrip(r)
+}
+
+func isSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '_' || r == '.' || r == ':'
+}
+
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,59 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(Header, "abc-123_x.y:z")
+	assert.Equal(t, "abc-123_x.y:z", FromRequest(r))
+
+	r.Header.Set(Header, "bad id\nwith injection")
+	r.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", FromRequest(r))
+
+	r.Header.Del(TraceparentHeader)
+	assert.Len(t, FromRequest(r), 36, "new uuid is generated")
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for header, ok := range map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": true,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01": false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": false,
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01":   false,
+		"": false,
+	} {
+		_, parsed := ParseTraceparent(header)
+		assert.Equal(t, ok, parsed, header)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	req, err := http.NewRequestWithContext(WithContext(context.Background(), "req-1"), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, "req-1", got)
+	assert.Empty(t, req.Header.Get(Header), "original request is not modified")
+}
@@ -2,18 +2,17 @@ package apihelper
 
 import (
 	"errors"
-	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
 
-	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tenancy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -113,6 +112,9 @@ func GetTestGinContext(w *httptest.ResponseRecorder) *gin.Context {
 // //nolint: gosec, - it's naming only!
 const authTokenHeader = "X-AUTH-TOKEN"
 
+// AuthTokenHeader - header with api key of machine client.
+const AuthTokenHeader = authTokenHeader
+
 func SetAPITokenToGinCtx[T any](c *gin.Context, token T) {
 	c.Set(authTokenHeader, token)
 }
@@ -135,47 +137,15 @@ type (
 	QueryParamName = string
 
 	AllowForSearchField string
-
-	sortField string
-	sortOrder string
 )
 
 const (
 	IndexNameParam QueryParamName = "index"     // redefine index name.
 	TenantIDParam  QueryParamName = "tenant_id" // redefine index name.
-
-	fromDateParam      QueryParamName = "from_date" // from created_at date time.Format(time.RFC3339)
-	toDateParam        QueryParamName = "to_date"   // to created_at date time.Format(time.RFC3339)
-	searchPatternParam QueryParamName = "search"    // search pattern in complex endpoint for multi-field searching.
-	cursorsParam       QueryParamName = "cursor"    // comma separated array of string values
-
-	pageParam    QueryParamName = "page"     // >= 0.
-	limitParam   QueryParamName = "limit"    // >0 and <= 10 000.
-	sortByParam  QueryParamName = "sort_by"  // name_of_field order by.
-	orderByParam QueryParamName = "order_by" // asc, desc.
 )
 
-const (
-	none      sortField = ""
-	CreatedAt sortField = "created_at"
-)
-
-const (
-	Asc  sortOrder = "asc"
-	Desc sortOrder = "desc"
-)
-
-const (
-	defaultPageValue       = 0
-	defaultLimitParamValue = 10
-	maxPageParamValue      = math.MaxInt
-	MaxLimitParamValue     = 10000
-)
-
-var (
-	allSortByFields      = [...]sortField{CreatedAt} // MUST BE NOT EMPTY! @see ParseSortByAndOrderByParams func.
-	allOrderByDirections = [...]sortOrder{Asc, Desc} // MUST BE NOT EMPTY! @see ParseSortByAndOrderByParams func.
-)
+// MaxLimitParamValue - max value of `limit` query param, @see SearchRequest.
+const MaxLimitParamValue = 10000
 
 const (
 	IDField                 AllowForSearchField = "id"
@@ -183,36 +153,6 @@ const (
 	NestedSubjectEmailField AllowForSearchField = "subject.data.email"
 )
 
-func ParseSortByAndOrderByParams(
-	c *gin.Context,
-) (sortBy []string, orderBy []string) {
-	listOfSortBy := ConvertToEnumsSliceWithDefault(
-		c.QueryArray(sortByParam),
-		allSortByFields[:],
-		allSortByFields[0],
-	)
-
-	listOfOrderBy := ConvertToEnumsSliceWithDefault(
-		c.QueryArray(orderByParam),
-		allOrderByDirections[:],
-		allOrderByDirections[0],
-	)
-
-	if len(listOfSortBy) > 0 && strings.EqualFold(string(listOfSortBy[0]), string(none)) ||
-		len(listOfSortBy) != len(listOfOrderBy) {
-		return []string{string(allSortByFields[0])}, []string{string(allOrderByDirections[0])}
-	}
-
-	return FastUnsafeConvertToStringSlice(listOfSortBy), FastUnsafeConvertToStringSlice(listOfOrderBy)
-}
-
-func ParsePageAndLimitPaginationOptionsByParams(c *gin.Context) (page int, limit int) {
-	page = GetIntFromStr(c.Query(pageParam), defaultPageValue, defaultPageValue, maxPageParamValue)
-	limit = GetIntFromStr(c.Query(limitParam), defaultLimitParamValue, 1, MaxLimitParamValue)
-
-	return page, limit
-}
-
 func FastUnsafeConvertToStringSlice[T ~string](ss []T) []string {
 	// It's more cheap rather than :
 	// type T string
@@ -227,123 +167,6 @@ func FastUnsafeConvertToStringSlice[T ~string](ss []T) []string {
 	return *(*[]string)(unsafe.Pointer(&ss))
 }
 
-// ParseQueryParamsForComplexSearch - ParseQueryParamsForComplexSearch.
-// //nolint: gocritic,  - here is ok.
-func ParseQueryParamsForComplexSearch(c *gin.Context, endpointPath string) (
-	tenantID string,
-	index string,
-	fromTime time.Time,
-	toTime time.Time,
-	searchPattern string,
-	successParse bool,
-) {
-	index, tenantID, successParse = ParseQueryIndexAndTenantParamsOnly(c, endpointPath)
-	if !successParse {
-		return index, tenantID, fromTime, toTime, searchPattern, successParse
-	}
-
-	fromTime, toTime, successParse = ParseQueryDateParamsOnly(c, endpointPath)
-	if !successParse {
-		return index, tenantID, fromTime, toTime, searchPattern, successParse
-	}
-
-	searchPattern, successParse = ParseQuerySearchPatternParamOnly(c, endpointPath)
-
-	return index, tenantID, fromTime, toTime, searchPattern, successParse
-}
-
-func ParseQueryIndexAndTenantParamsOnly(c *gin.Context, endpointPath string) (
-	tenantID string,
-	index string,
-	successParse bool,
-) {
-	tenantID = strings.TrimSpace(c.Query(TenantIDParam))
-	if tenantID == "" {
-		c.JSON(http.StatusBadRequest, apierror.APIError{
-			Type: "reports-service/issues/bad_query_param/tenant_id",
-			Title: "Query param is empty." +
-				"The reason might be that `" + TenantIDParam + "` is empty." +
-				"Please check this query param.",
-			Status: http.StatusBadRequest,
-			Detail: "Check json body. Reason query param:" + TenantIDParam + " " +
-				"@See more in reports-service/app/internal/servers/api/controller/... -> ",
-			Instance: endpointPath,
-		})
-
-		return tenantID, index, false
-	}
-
-	index = strings.TrimSpace(c.Query(IndexNameParam))
-
-	return index, tenantID, true
-}
-
-func ParseQuerySearchPatternParamOnly(c *gin.Context, _ string) (
-	searchPattern string,
-	successParse bool,
-) {
-	return c.Query(searchPatternParam), true
-}
-
-func ParseQueryDateParamsOnly(c *gin.Context, endpointPath string) (
-	fromTime time.Time,
-	toTime time.Time,
-	successParse bool,
-) {
-	fromDate := c.Query(fromDateParam)
-	toDate := c.Query(toDateParam)
-
-	var err error
-	if fromDate != "" {
-		fromTime, err = time.Parse(time.RFC3339, fromDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, apierror.APIError{
-				Type: "reports-service/issues/bad_query_param/from",
-				Title: "Query param `from_data` could not parse as time.Time obj. " +
-					"The reason might be that `" + fromDateParam +
-					"` query param contain time string represent not equivalent of format time.RFC3339" +
-					"Please check that query param.",
-				Status: http.StatusBadRequest,
-				Detail: "Check json body. Reason query param:" + fromDateParam + " " +
-					"@See more in reports-service/app/internal/servers/api/controller/... -> ",
-				Instance: endpointPath,
-			})
-
-			return fromTime, toTime, false
-		}
-	}
-
-	if toDate != "" {
-		toTime, err = time.Parse(time.RFC3339, toDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, apierror.APIError{
-				Type: "reports-service/issues/bad_query_param/to",
-				Title: "Query param `to_data` could not parse as time.Time obj. " +
-					"The reason might be that `" + toDateParam +
-					"` query param contain time string represent not equivalent of format time.RFC3339" +
-					"Please check that query param.",
-				Status: http.StatusBadRequest,
-				Detail: "Check json body. Reason query param:" + toDateParam + " " +
-					"@See more in reports-service/app/internal/servers/api/controller/... -> ",
-				Instance: endpointPath,
-			})
-
-			return fromTime, toTime, false
-		}
-	}
-
-	return fromTime, toTime, true
-}
-
-// ParseCursorsOnly - This function extracts multiple cursor values from the query parameters.
-func ParseCursorsOnly(c *gin.Context) []string {
-	if cursors := c.QueryArray(cursorsParam); len(cursors) != 0 {
-		return cursors
-	}
-
-	return nil
-}
-
 type (
 	keyType        string
 	queryParamType string
@@ -357,6 +180,8 @@ const (
 const (
 	tenantID         keyType = "tenantID"
 	requestingUserID keyType = "requestingUserID"
+	sessionUserID    keyType = "sessionUserID"
+	session          keyType = "session"
 )
 
 var (
@@ -365,9 +190,23 @@ var (
 	errEmptyUUID = errors.New("empty UUID param")
 )
 
-// SetTenantUUIDForRequest - set tenant uuid from jwt token.
+// SetTenantUUIDForRequest - set tenant uuid from jwt token. Context of request gets tenant too, so DB queries of
+// request are scoped by it, @see tenancy.Plugin.
 func SetTenantUUIDForRequest(c *gin.Context, tID uuid.UUID) {
 	StoreInGinCtxKV(c, tenantID, tID.String())
+	c.Request = c.Request.WithContext(tenancy.WithTenant(c.Request.Context(), tID))
+}
+
+// GetTenantUUIDFromRequest - get tenant uuid stored by auth middleware.
+func GetTenantUUIDFromRequest(c *gin.Context) (uuid.UUID, bool) {
+	v := c.GetString(string(tenantID))
+	if v == "" {
+		return emptyUUID, false
+	}
+
+	tID, err := uuid.Parse(v)
+
+	return tID, err == nil
 }
 
 // SetRequestingUserUUIDForRequest - store requesting user id from JWT token.
@@ -375,6 +214,36 @@ func SetRequestingUserUUIDForRequest(c *gin.Context, userID uuid.UUID) {
 	StoreInGinCtxKV(c, requestingUserID, userID.String())
 }
 
+// SetSessionForRequest - store validated session (and its user id) of requesting user.
+func SetSessionForRequest(c *gin.Context, s *tables.Session) {
+	StoreInGinCtxKV(c, session, s)
+	StoreInGinCtxKV(c, sessionUserID, s.UserID)
+}
+
+// GetSessionFromRequest - get session stored by session auth middleware.
+func GetSessionFromRequest(c *gin.Context) (*tables.Session, bool) {
+	v, exist := c.Get(string(session))
+	if !exist {
+		return nil, false
+	}
+
+	s, ok := v.(*tables.Session)
+
+	return s, ok
+}
+
+// GetSessionUserID - get user id stored by session auth middleware.
+func GetSessionUserID(c *gin.Context) (int, bool) {
+	v, exist := c.Get(string(sessionUserID))
+	if !exist {
+		return 0, false
+	}
+
+	id, ok := v.(int)
+
+	return id, ok
+}
+
 // StoreInGinCtxKV - store CUSTOM key-value pairs into gin context. For DI purposes.
 func StoreInGinCtxKV(c *gin.Context, key keyType, value any) {
 	c.Set(string(key), value)
@@ -0,0 +1,191 @@
+package apihelper
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+// Headers of conditional requests (RFC 9110 section 13).
+const (
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfMatchHeader         = "If-Match"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+)
+
+// hashETagLen - bytes of sha256 in hash etag, collisions of 128 bits are improbable.
+const hashETagLen = 16
+
+// ETag - entity tag of representation. Strong etag changes with any change of representation bytes (e.g. version
+// column of resource), weak etag - with semantic change only (e.g. hash of response, which can be compressed).
+type ETag struct {
+	Tag  string
+	Weak bool
+}
+
+// StrongETag - strong etag with tag (without quotes).
+func StrongETag(tag string) ETag {
+	return ETag{Tag: tag}
+}
+
+// WeakETag - weak etag with tag (without quotes).
+func WeakETag(tag string) ETag {
+	return ETag{Tag: tag, Weak: true}
+}
+
+// VersionETag - strong etag of resource version column.
+func VersionETag(version int64) ETag {
+	return StrongETag(strconv.FormatInt(version, 10))
+}
+
+// HashETag - weak etag of response body.
+func HashETag(body []byte) ETag {
+	sum := sha256.Sum256(body)
+
+	return WeakETag(base64.RawURLEncoding.EncodeToString(sum[:hashETagLen]))
+}
+
+// String - header value, e.g. `"42"` or `W/"42"`.
+func (e ETag) String() string {
+	if e.Weak {
+		return `W/"` + e.Tag + `"`
+	}
+
+	return `"` + e.Tag + `"`
+}
+
+// IsZero - etag isn't set.
+func (e ETag) IsZero() bool {
+	return e == ETag{}
+}
+
+// ParseETag - parse single etag, false if value is malformed.
+func ParseETag(s string) (ETag, bool) {
+	s = strings.TrimSpace(s)
+
+	var e ETag
+	if strings.HasPrefix(s, "W/") {
+		e.Weak, s = true, s[2:]
+	}
+
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' || strings.Contains(s[1:len(s)-1], `"`) {
+		return ETag{}, false
+	}
+
+	e.Tag = s[1 : len(s)-1]
+
+	return e, true
+}
+
+// ParseETags - parse list of etags of `If-Match`/`If-None-Match` header, wildcard is true for `*`.
+// Malformed etags are skipped.
+func ParseETags(header string) (tags []ETag, wildcard bool) {
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s == "*" {
+			wildcard = true
+
+			continue
+		}
+
+		if e, ok := ParseETag(s); ok {
+			tags = append(tags, e)
+		}
+	}
+
+	return tags, wildcard
+}
+
+// IsNotModified - representation with etag and lastModified isn't changed since version of client, RFC 9110 13.2.2:
+// `If-None-Match` (weak comparison) has priority over `If-Modified-Since`. Zero etag or lastModified are unknown.
+func IsNotModified(r *http.Request, etag ETag, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := r.Header.Get(IfNoneMatchHeader); header != "" {
+		if etag.IsZero() {
+			return false
+		}
+
+		tags, wildcard := ParseETags(header)
+		if wildcard {
+			return true
+		}
+
+		for _, t := range tags {
+			if t.Tag == etag.Tag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if header := r.Header.Get(IfModifiedSinceHeader); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// NotModified - set `ETag` and `Last-Modified` headers (zero values are skipped) and respond 304 if representation
+// isn't modified since version of client. Returns true if response is done, handler must return.
+func NotModified(c *gin.Context, etag ETag, lastModified time.Time) bool {
+	if !etag.IsZero() {
+		c.Header(ETagHeader, etag.String())
+	}
+
+	if !lastModified.IsZero() {
+		c.Header(LastModifiedHeader, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if !IsNotModified(c.Request, etag, lastModified) {
+		return false
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+
+	return true
+}
+
+// HasIfMatch - request has `If-Match` header, so handler must check it by CheckIfMatch.
+func HasIfMatch(c *gin.Context) bool {
+	return c.GetHeader(IfMatchHeader) != ""
+}
+
+// CheckIfMatch - optimistic concurrency: `If-Match` header (if set) must match current etag of resource by strong
+// comparison, `*` matches any existing resource. Responds 412 `precondition_failed` and returns false otherwise.
+func CheckIfMatch(c *gin.Context, current ETag) bool {
+	header := c.GetHeader(IfMatchHeader)
+	if header == "" {
+		return true
+	}
+
+	tags, wildcard := ParseETags(header)
+	if wildcard {
+		return true
+	}
+
+	for _, t := range tags {
+		if !t.Weak && !current.Weak && t.Tag == current.Tag {
+			return true
+		}
+	}
+
+	apierror.Abort(c, apperror.ErrPreconditionFailed.WithDetail("resource is modified, current etag is "+
+		current.String()).With("etag", current.String()))
+
+	return false
+}
@@ -0,0 +1,79 @@
+package apihelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseETags(t *testing.T) {
+	tags, wildcard := ParseETags(`"a", W/"b" , bad, "c"d", *`)
+	assert.True(t, wildcard)
+	assert.Equal(t, []ETag{StrongETag("a"), WeakETag("b")}, tags)
+
+	assert.Equal(t, `"42"`, VersionETag(42).String())
+	assert.Equal(t, `W/"x"`, WeakETag("x").String())
+	assert.Equal(t, HashETag([]byte("body")), HashETag([]byte("body")))
+	assert.NotEqual(t, HashETag([]byte("body")), HashETag([]byte("other")))
+}
+
+func TestIsNotModified(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		header      map[string]string
+		notModified bool
+	}{
+		{name: "no conditions", method: http.MethodGet},
+		{name: "weak match", method: http.MethodGet, header: map[string]string{IfNoneMatchHeader: `W/"7"`},
+			notModified: true},
+		{name: "no match", method: http.MethodGet, header: map[string]string{IfNoneMatchHeader: `"6", "5"`}},
+		{name: "wildcard", method: http.MethodGet, header: map[string]string{IfNoneMatchHeader: `*`}, notModified: true},
+		{name: "etag has priority", method: http.MethodGet, header: map[string]string{
+			IfNoneMatchHeader:     `"6"`,
+			IfModifiedSinceHeader: modified.Format(http.TimeFormat),
+		}},
+		{name: "not modified since", method: http.MethodGet,
+			header: map[string]string{IfModifiedSinceHeader: modified.Format(http.TimeFormat)}, notModified: true},
+		{name: "modified since", method: http.MethodGet,
+			header: map[string]string{IfModifiedSinceHeader: modified.Add(-time.Second).Format(http.TimeFormat)}},
+		{name: "not get", method: http.MethodPost, header: map[string]string{IfNoneMatchHeader: `"7"`}},
+	} {
+		r := httptest.NewRequest(tc.method, "/", nil)
+		for k, v := range tc.header {
+			r.Header.Set(k, v)
+		}
+
+		assert.Equal(t, tc.notModified, IsNotModified(r, VersionETag(7), modified), tc.name)
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	for _, tc := range []struct {
+		ifMatch string
+		ok      bool
+	}{
+		{"", true},
+		{`"7"`, true},
+		{`"6", "7"`, true},
+		{`*`, true},
+		{`"6"`, false},
+		{`W/"7"`, false}, // strong comparison.
+	} {
+		w := httptest.NewRecorder()
+		c := GetTestGinContext(w)
+		c.Request.Header.Set(IfMatchHeader, tc.ifMatch)
+
+		assert.Equal(t, tc.ok, CheckIfMatch(c, VersionETag(7)), tc.ifMatch)
+
+		if !tc.ok {
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+			assert.Contains(t, w.Body.String(), `"etag":"\"7\""`)
+		}
+	}
+}
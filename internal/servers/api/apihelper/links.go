@@ -0,0 +1,35 @@
+package apihelper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/pagination"
+)
+
+// LinkHeader - RFC 8288 web linking header.
+const LinkHeader = "Link"
+
+// SetPageLinks - set `Link` header with next/prev page urls (current url with replaced cursor), empty cursor skipped.
+func SetPageLinks(c *gin.Context, next, prev string) {
+	links := make([]string, 0, 2) //nolint:gomnd // next and prev.
+
+	for _, l := range [...]struct{ rel, cursor string }{{"next", next}, {"prev", prev}} {
+		if l.cursor == "" {
+			continue
+		}
+
+		u := *c.Request.URL
+		q := u.Query()
+		q.Set(pagination.Param, l.cursor)
+		u.RawQuery = q.Encode()
+
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), l.rel))
+	}
+
+	if len(links) > 0 {
+		c.Header(LinkHeader, strings.Join(links, ", "))
+	}
+}
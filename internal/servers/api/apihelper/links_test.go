@@ -0,0 +1,22 @@
+package apihelper
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPageLinks(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := GetTestGinContext(w)
+	c.Request.URL = &url.URL{Path: "/api/v1/api-keys", RawQuery: "sort=-name&cursor=old"}
+
+	SetPageLinks(c, "n1", "")
+	assert.Equal(t, `</api/v1/api-keys?cursor=n1&sort=-name>; rel="next"`, w.Header().Get(LinkHeader))
+
+	SetPageLinks(c, "n2", "p2")
+	assert.Equal(t, `</api/v1/api-keys?cursor=n2&sort=-name>; rel="next", `+
+		`</api/v1/api-keys?cursor=p2&sort=-name>; rel="prev"`, w.Header().Get(LinkHeader))
+}
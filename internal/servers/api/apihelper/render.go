@@ -0,0 +1,240 @@
+package apihelper
+
+import (
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+// Media types of lists, @see RenderList.
+const (
+	MIMEJSON     = binding.MIMEJSON
+	MIMENDJSON   = "application/x-ndjson"
+	MIMECSV      = "text/csv"
+	MIMEMsgPack  = binding.MIMEMSGPACK  // application/x-msgpack
+	MIMEMsgPack2 = binding.MIMEMSGPACK2 // application/msgpack
+)
+
+// ListFormats - media types of RenderList, JSON is default (no `Accept` header).
+var ListFormats = []string{MIMEJSON, MIMENDJSON, MIMECSV, MIMEMsgPack2, MIMEMsgPack}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// RenderList - render slice of items in media type of `Accept` header (@see ListFormats): JSON array,
+// NDJSON (item per line), CSV (header of json names of fields, nested values are JSON) or MessagePack.
+// 406 if no type is acceptable.
+func RenderList(c *gin.Context, status int, items any) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	switch format := c.NegotiateFormat(ListFormats...); format {
+	case MIMEJSON:
+		c.JSON(status, items)
+	case MIMENDJSON:
+		c.Header("Content-Type", MIMENDJSON+"; charset=utf-8")
+		c.Status(status)
+
+		if err := writeNDJSON(c.Writer, items); err != nil {
+			_ = c.Error(err)
+		}
+	case MIMECSV:
+		c.Header("Content-Type", MIMECSV+"; charset=utf-8")
+		c.Status(status)
+
+		if err := writeCSV(c.Writer, items); err != nil {
+			_ = c.Error(err)
+		}
+	case MIMEMsgPack, MIMEMsgPack2:
+		c.Render(status, render.MsgPack{Data: items})
+	default:
+		apierror.Abort(c, apperror.ErrNotAcceptable.WithDetail("supported media types: "+
+			strings.Join(ListFormats, ", ")))
+	}
+}
+
+func writeNDJSON(w http.ResponseWriter, items any) error {
+	list, err := listValue(items)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for i := 0; i < list.Len(); i++ {
+		if err := enc.Encode(list.Index(i).Interface()); err != nil {
+			return fmt.Errorf("write ndjson: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeCSV - columns are fields of item struct (embedded structs are flattened), item which isn't struct
+// or has own text form (encoding.TextMarshaler) is written as single `value` column.
+func writeCSV(w http.ResponseWriter, items any) error {
+	list, err := listValue(items)
+	if err != nil {
+		return err
+	}
+
+	itemType := list.Type().Elem()
+	for itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+
+	scalar := csvScalar(itemType)
+	columns := csvColumns(itemType, nil)
+
+	cw := csv.NewWriter(w)
+
+	header := []string{"value"}
+	if !scalar {
+		header = header[:0]
+		for _, col := range columns {
+			header = append(header, col.name)
+		}
+	}
+
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	row := make([]string, len(header))
+	for i := 0; i < list.Len(); i++ {
+		item := reflect.Indirect(list.Index(i))
+
+		if scalar {
+			row[0] = csvCell(item)
+		}
+
+		for j, col := range columns {
+			row[j] = ""
+			if item.IsValid() {
+				if field, err := item.FieldByIndexErr(col.index); err == nil {
+					row[j] = csvCell(field)
+				}
+			}
+		}
+
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func listValue(items any) (reflect.Value, error) {
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("render list: %T isn't slice", items)
+	}
+
+	return list, nil
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvScalar - value of type is single cell: not struct or struct with own text form, e.g. time.Time.
+func csvScalar(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || t.Implements(textMarshalerType)
+}
+
+// csvColumns - exported fields of struct with names of `json` tags, fields with `json:"-"` are skipped.
+func csvColumns(t reflect.Type, index []int) []csvColumn {
+	if csvScalar(t) {
+		return nil
+	}
+
+	var columns []csvColumn
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct { // fields of embedded struct, like json.
+			columns = append(columns, csvColumns(f.Type, fieldIndex)...)
+
+			continue
+		}
+
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		columns = append(columns, csvColumn{name: name, index: fieldIndex})
+	}
+
+	return columns
+}
+
+// csvCell - text of value: scalars as is, time in RFC 3339, nil as empty cell, the rest as JSON.
+// Text which starts with formula symbol is prefixed by `'`, so spreadsheets don't evaluate it.
+func csvCell(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		text, err := x.MarshalText()
+		if err != nil {
+			return ""
+		}
+
+		return escapeFormula(string(text))
+	}
+
+	switch v.Kind() { //nolint:exhaustive // rest kinds are JSON.
+	case reflect.String:
+		return escapeFormula(v.String())
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	default:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+
+		return string(b)
+	}
+}
+
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,95 @@
+package apihelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+type (
+	renderBase struct {
+		ID int `json:"id"`
+	}
+
+	renderItem struct {
+		renderBase
+		Name    string            `json:"name"`
+		Created time.Time         `json:"created_at"`
+		Expired *time.Time        `json:"expired_at,omitempty"`
+		Tags    map[string]string `json:"tags"`
+		Secret  string            `json:"-"`
+	}
+)
+
+func TestRenderList(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	items := []renderItem{
+		{renderBase: renderBase{ID: 1}, Name: "a, \"b\"", Created: created, Tags: map[string]string{"k": "v"}},
+		{renderBase: renderBase{ID: 2}, Name: "=cmd", Created: created, Expired: &created, Secret: "x"},
+	}
+
+	do := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c := GetTestGinContext(w)
+		c.Request.Header.Set("Accept", accept)
+
+		RenderList(c, http.StatusOK, items)
+
+		return w
+	}
+
+	w := do("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.JSONEq(t, `[
+		{"id":1,"name":"a, \"b\"","created_at":"2024-01-02T03:04:05Z","tags":{"k":"v"}},
+		{"id":2,"name":"=cmd","created_at":"2024-01-02T03:04:05Z","expired_at":"2024-01-02T03:04:05Z","tags":null}
+	]`, w.Body.String())
+
+	w = do("text/csv")
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name,created_at,expired_at,tags\n"+
+		"1,\"a, \"\"b\"\"\",2024-01-02T03:04:05Z,,\"{\"\"k\"\":\"\"v\"\"}\"\n"+
+		"2,'=cmd,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z,null\n", w.Body.String())
+
+	w = do("application/x-ndjson")
+	assert.Equal(t, `{"id":1,"name":"a, \"b\"","created_at":"2024-01-02T03:04:05Z","tags":{"k":"v"}}`+"\n"+
+		`{"id":2,"name":"=cmd","created_at":"2024-01-02T03:04:05Z","expired_at":"2024-01-02T03:04:05Z","tags":null}`+
+		"\n", w.Body.String())
+
+	w = do("application/msgpack")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var decoded []map[string]any
+	assert.NoError(t, codec.NewDecoderBytes(w.Body.Bytes(), &codec.MsgpackHandle{}).Decode(&decoded))
+	assert.Len(t, decoded, 2)
+
+	w = do("application/xml")
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	w = httptest.NewRecorder()
+	c := GetTestGinContext(w)
+	c.Request.Header.Set("Accept", "text/csv")
+	RenderList(c, http.StatusOK, []string{"x", "+y"})
+	assert.Equal(t, "value\nx\n'+y\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	c = GetTestGinContext(w)
+	c.Request.Header.Set("Accept", "text/csv")
+	RenderList(c, http.StatusOK, []time.Time{created})
+	assert.Equal(t, "value\n2024-01-02T03:04:05Z\n", w.Body.String(), "text marshaler is single value")
+
+	w = do("application/x-msgpack")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/msgpack; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestEscapeFormula(t *testing.T) {
+	for in, out := range map[string]string{"": "", "a": "a", "-1": "'-1", "@x": "'@x"} {
+		assert.Equal(t, out, escapeFormula(in), in)
+	}
+}
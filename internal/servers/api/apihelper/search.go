@@ -0,0 +1,86 @@
+package apihelper
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/filter"
+	"github.com/imperiuse/go-app-skeleton/internal/database/fulltext"
+	"github.com/imperiuse/go-app-skeleton/internal/database/pagination"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/daterange"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+)
+
+// SearchRequest - query params of search endpoints. Bounds are validated by `binding` tags, @see ParseSearchRequest.
+type SearchRequest struct {
+	TenantID      string   `form:"tenant_id" binding:"required,uuid"`
+	Index         string   `form:"index" binding:"max=255"`
+	FromDate      string   `form:"from_date" binding:"max=64"` // @see daterange package for format.
+	ToDate        string   `form:"to_date" binding:"max=64"`
+	TZ            string   `form:"tz" binding:"max=64"`       // IANA time zone of local dates, e.g. `Europe/Berlin`.
+	SearchPattern string   `form:"search" binding:"max=1024"` // multi-field search pattern.
+	Cursor        string   `form:"cursor" binding:"max=4096"` // keyset cursor, instead of Page.
+	Page          int      `form:"page,default=0" binding:"min=0,max=1000000"`
+	Limit         int      `form:"limit,default=10" binding:"min=1,max=10000"` // @see MaxLimitParamValue.
+	Sort          string   `form:"sort" binding:"max=256"`                     // e.g. `-created_at,name`.
+	Filter        []string `form:"filter" binding:"max=16,dive,max=2048"`      // e.g. `status:eq:done`.
+
+	Order sorting.Sort    `form:"-"` // parsed Sort, always ends with primary key, @see sorting.Spec.
+	Where filter.Filter   `form:"-"` // parsed Filter, @see filter.Spec.
+	Dates daterange.Range `form:"-"` // parsed FromDate, ToDate: created_at >= From AND created_at < To.
+	Query fulltext.Query  `form:"-"` // parsed SearchPattern, @see fulltext.Index.Scope.
+}
+
+// SearchSpec - per-endpoint rules of search request.
+type SearchSpec struct {
+	Sort   *sorting.Spec
+	Filter *filter.Spec
+	Dates  *daterange.Parser
+}
+
+// ParseSearchRequest - bind and validate query params of search request, `sort`, `filter` and dates are checked by
+// spec of endpoint. It doesn't write response, returned error is apperror, so it can be used by any handler.
+func ParseSearchRequest(values url.Values, spec SearchSpec) (SearchRequest, error) {
+	var req SearchRequest
+	if err := validation.BindValues(values, &req); err != nil {
+		return req, err
+	}
+
+	req.TenantID = strings.TrimSpace(req.TenantID)
+	req.Index = strings.TrimSpace(req.Index)
+	req.Query = fulltext.Parse(req.SearchPattern)
+
+	dates, err := spec.Dates.Parse(req.FromDate, req.ToDate, req.TZ)
+	if err != nil {
+		return req, err
+	}
+
+	req.Dates = dates
+
+	order, err := spec.Sort.Parse(req.Sort)
+	if err != nil {
+		return req, err
+	}
+
+	req.Order = order
+
+	where, err := spec.Filter.Parse(req.Filter...)
+	if err != nil {
+		return req, err
+	}
+
+	req.Where = where
+
+	return req, nil
+}
+
+// PageRequest - keyset page request, cursor is validated against Order. Use it instead of Offset for big tables.
+func (r SearchRequest) PageRequest(codec *pagination.Codec) (pagination.Request, error) {
+	return codec.NewRequest(r.Order, r.Cursor, r.Limit)
+}
+
+// Offset - offset of page.
+func (r SearchRequest) Offset() int {
+	return r.Page * r.Limit
+}
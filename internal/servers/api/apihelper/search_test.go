@@ -0,0 +1,75 @@
+package apihelper
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/filter"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/daterange"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+)
+
+const tenant = "0b6c9a43-2c1f-4a8e-9d4f-6f1f6f3c2d11"
+
+var spec = SearchSpec{
+	Sort:   sorting.NewSpec(map[string]string{"created_at": "created_at", "name": "name"}, "id", "-created_at"),
+	Filter: filter.NewSpec(map[string]filter.Field{"status": {Column: "status", Type: filter.String}}),
+	Dates:  daterange.New(daterange.Config{}),
+}
+
+func TestParseSearchRequest(t *testing.T) {
+	req, err := ParseSearchRequest(url.Values{
+		"tenant_id": {tenant},
+		"index":     {"reports"},
+		"search":    {"time* -debug"},
+		"from_date": {"2024-01-01"},
+		"to_date":   {"2024-01-31"},
+		"cursor":    {"opaque"},
+		"limit":     {"50"},
+		"sort":      {"name"},
+		"filter":    {"status:eq:done"},
+	}, spec)
+	require.NoError(t, err)
+
+	assert.Equal(t, tenant, req.TenantID)
+	assert.Equal(t, "reports", req.Index, "index and tenant are not swapped")
+	assert.Equal(t, "'time':* & !'debug'", req.Query.String())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), req.Dates.From)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), req.Dates.To, "whole last day")
+	assert.Equal(t, "opaque", req.Cursor)
+	assert.Equal(t, 0, req.Page, "default")
+	assert.Equal(t, 50, req.Limit)
+	assert.Equal(t, "name", req.Order.String())
+	assert.Equal(t, []any{"done"}, req.Where[0].Values)
+
+	req, err = ParseSearchRequest(url.Values{"tenant_id": {tenant}}, spec)
+	require.NoError(t, err)
+	assert.Equal(t, 10, req.Limit, "default")
+	assert.Equal(t, "-created_at", req.Order.String(), "default")
+
+	_, err = ParseSearchRequest(url.Values{"limit": {"0"}, "page": {"-1"}}, spec)
+
+	e := apperror.From(err)
+	assert.Equal(t, apperror.ErrValidation.Code, e.Code)
+	assert.Len(t, e.Extensions[validation.InvalidParamsMember], 3, "tenant_id, limit, page")
+
+	_, err = ParseSearchRequest(url.Values{"tenant_id": {tenant}, "sort": {"password"}}, spec)
+	assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code)
+
+	_, err = ParseSearchRequest(url.Values{"tenant_id": {tenant}, "filter": {"name:eq:x"}}, spec)
+	assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code)
+
+	_, err = ParseSearchRequest(url.Values{"tenant_id": {tenant}, "from_date": {"the day before"}}, spec)
+	assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code)
+
+	_, err = ParseSearchRequest(url.Values{
+		"tenant_id": {tenant}, "from_date": {"2024-01-02T00:00:00Z"}, "to_date": {"2024-01-01T00:00:00Z"},
+	}, spec)
+	assert.Equal(t, apperror.ErrBadQueryParam.Code, apperror.From(err).Code)
+}
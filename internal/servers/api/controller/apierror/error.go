@@ -1,14 +1,95 @@
 package apierror
 
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+)
+
 // Inspired by this article https://www.linkedin.com/pulse/rfc-7807-error-handling-standard-apis-david-rold%C3%A1n-mart%C3%ADnez
 // RFC-7807
 
+const (
+	// ContentType - media type of problem details.
+	ContentType = "application/problem+json"
+	// TypePrefix - prefix of problem type URI (documentation page of problem type, @see controller/problems).
+	TypePrefix = "/api/v1/problems/"
+)
+
 // APIError defines the structure for describing API errors, following RFC-7807.
 // //nolint: lll, this is usefully len comment line
 type APIError struct {
-	Type     string `json:"type" example:"reports-service/issues/token-generation-error"` // A URI reference that identifies the problem type.
-	Title    string `json:"title" example:"Name of the problem or an error"`              // A short, human-readable summary of the problem type.
-	Status   int    `json:"status" example:"500"`                                         // The HTTP status code for this occurrence of the problem.
-	Detail   string `json:"detail" example:"Description of the problem"`                  // A human-readable explanation specific to this occurrence.
-	Instance string `json:"instance" example:"GET /api/v1/some"`                          // A URI reference that identifies the specific occurrence of the problem.
+	Type       string         `json:"type" example:"/api/v1/problems/internal"`                            // A URI reference that identifies the problem type.
+	Title      string         `json:"title" example:"Name of the problem or an error"`                     // A short, human-readable summary of the problem type.
+	Status     int            `json:"status" example:"500"`                                                // The HTTP status code for this occurrence of the problem.
+	Detail     string         `json:"detail" example:"Description of the problem"`                         // A human-readable explanation specific to this occurrence.
+	Instance   string         `json:"instance" example:"GET /api/v1/some"`                                 // A URI reference that identifies the specific occurrence of the problem.
+	RequestID  string         `json:"request_id,omitempty" example:"8b6c1f3e-0c2e-4c5e-9a59-3f0f2b1f7d4a"` // Extension member: id of request, for correlation with logs.
+	Extensions map[string]any `json:"-" swaggerignore:"true"`                                              // Other extension members, they are marshaled as top level members.
+}
+
+// FromError - problem details of error, @see apperror.From.
+func FromError(c *gin.Context, err error) APIError {
+	e := apperror.From(err)
+
+	return APIError{
+		Type:       TypePrefix + e.Code,
+		Title:      e.Title,
+		Status:     e.Status,
+		Detail:     e.Detail,
+		Instance:   c.Request.Method + " " + c.Request.URL.Path,
+		RequestID:  requestid.FromContext(c.Request.Context()),
+		Extensions: e.Extensions,
+	}
+}
+
+// MarshalJSON - extension members are top level members of problem details.
+func (e APIError) MarshalJSON() ([]byte, error) {
+	type plain APIError
+
+	if len(e.Extensions) == 0 {
+		return json.Marshal(plain(e))
+	}
+
+	b, err := json.Marshal(plain(e))
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]any, len(e.Extensions)+6) //nolint: gomnd // number of standard members.
+	for k, v := range e.Extensions {
+		members[k] = v
+	}
+
+	// standard members can't be overridden by extensions.
+	var standard map[string]any
+	if err = json.Unmarshal(b, &standard); err != nil {
+		return nil, err
+	}
+
+	for k, v := range standard {
+		members[k] = v
+	}
+
+	return json.Marshal(members)
+}
+
+// Abort - abort request with problem details of err, err is attached to context (@see c.Error),
+// so it's logged and counted by error middleware.
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	c.Abort()
+	Render(c, err)
+}
+
+// Render - write problem details of err as `application/problem+json` response.
+func Render(c *gin.Context, err error) {
+	problem := FromError(c, err)
+
+	c.Header("Content-Type", ContentType+"; charset=utf-8")
+	c.JSON(problem.Status, problem)
 }
@@ -0,0 +1,370 @@
+// Package apikey - http handlers for api keys management.
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/filter"
+	"github.com/imperiuse/go-app-skeleton/internal/database/fulltext"
+	"github.com/imperiuse/go-app-skeleton/internal/database/pagination"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+	apikeyService "github.com/imperiuse/go-app-skeleton/internal/services/apikey"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+type (
+	// Service - api keys service, @see services/apikey.Service.
+	Service interface {
+		Create(ctx context.Context, p apikeyService.CreateParams) (string, *tables.APIKey, error)
+		List(ctx context.Context, tenantID uuid.UUID, p apikeyService.ListParams) (pagination.Page[apikeyService.Hit], error)
+		Revoke(ctx context.Context, tenantID uuid.UUID, keyID int) error
+	}
+
+	// RightsProvider - rights of users, @see services/rbac.Service.
+	RightsProvider interface {
+		mw.PermissionChecker
+
+		UserRights(ctx context.Context, userID int) (rbac.Rights, error)
+	}
+
+	// Controller - api keys http controller.
+	Controller struct {
+		svc     Service
+		rights  RightsProvider
+		tenants mw.TenantProvider
+		cursors *pagination.Codec
+	}
+
+	// ListRequest - query params of api keys list, next/prev pages are in `Link` header.
+	ListRequest struct {
+		Sort   string   `form:"sort" binding:"max=256"`
+		Filter []string `form:"filter" binding:"max=16,dive,max=2048"`
+		Search string   `form:"search" binding:"max=1024"`
+		Cursor string   `form:"cursor" binding:"max=4096"`
+		Limit  int      `form:"limit,default=100" binding:"min=1,max=1000"`
+	}
+
+	// CreateRequest - create api key request body.
+	CreateRequest struct {
+		TenantID  uuid.UUID  `json:"tenant_id"` // required for users, ignored for api keys.
+		Name      string     `json:"name" binding:"required,max=128"`
+		Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+		ExpiredAt *time.Time `json:"expired_at"`
+	}
+
+	// APIKey - api key info for client (without secret).
+	APIKey struct {
+		ID         int        `json:"id"`
+		TenantID   uuid.UUID  `json:"tenant_id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		CreatedAt  time.Time  `json:"created_at"`
+		ExpiredAt  *time.Time `json:"expired_at,omitempty"`
+		LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+		RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+		Rank      float64 `json:"rank,omitempty"`      // full-text search relevance.
+		Highlight string  `json:"highlight,omitempty"` // escaped name with matched words in <mark></mark>.
+	}
+
+	// CreatedAPIKey - api key info with full key value, returned only once, on creation.
+	CreatedAPIKey struct {
+		APIKey
+		Key string `json:"key"`
+	}
+)
+
+// listSort - sortable fields of api keys list, newest first by default.
+var listSort = sorting.NewSpec(map[string]string{
+	"created_at":   "created_at",
+	"name":         "name",
+	"expired_at":   "expired_at",
+	"last_used_at": "last_used_at",
+}, "id", "-created_at")
+
+// listFilter - filterable fields of api keys list.
+var listFilter = filter.NewSpec(map[string]filter.Field{
+	"id":           {Column: "id", Type: filter.Int},
+	"name":         {Column: "name", Type: filter.String},
+	"prefix":       {Column: "prefix", Type: filter.String},
+	"created_by":   {Column: "created_by", Type: filter.Int},
+	"created_at":   {Column: "created_at", Type: filter.Time},
+	"expired_at":   {Column: "expired_at", Type: filter.Time},
+	"last_used_at": {Column: "last_used_at", Type: filter.Time},
+	"revoked_at":   {Column: "revoked_at", Type: filter.Time},
+})
+
+// New - constructor of api keys Controller.
+func New(svc Service, rights RightsProvider, tenants mw.TenantProvider, cursors *pagination.Codec) *Controller {
+	return &Controller{svc: svc, rights: rights, tenants: tenants, cursors: cursors}
+}
+
+// Register - register routes, all of them require `api_keys:manage` permission.
+func (ctrl *Controller) Register(_ *gin.RouterGroup, private *gin.RouterGroup) {
+	g := private.Group("/api-keys", mw.RequirePermission(ctrl.rights, rbac.ManageAPIKeys))
+
+	g.POST("", ctrl.create)
+	g.GET("", ctrl.list)
+	g.DELETE("/:id", ctrl.revoke)
+}
+
+// CreateAPIKey godoc
+// @Summary Create api key
+// @Description Create api key for machine client. Full key is returned only once!
+// @Description Scopes can't be wider than rights of caller. `roles:manage`, `tenants:manage` are granted only to users.
+// @Description tenant_id is required for users (`tenants:manage` permission is needed), ignored for api keys.
+// @Id CreateAPIKey
+// @Tags API keys
+// @Accept  json
+// @Produce  json
+// @Param request body CreateRequest true "api key"
+// @Success 201 {object} CreatedAPIKey
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/api-keys [post]
+func (ctrl *Controller) create(c *gin.Context) {
+	// tenant_id of body is resolved once for middlewares (status, idempotency) and handler,
+	// before binding: it reads body. @see mw.RequestTenant.
+	tenantID, ok := ctrl.resolveTenant(c)
+	if !ok || !mw.CheckTenantActive(c, ctrl.tenants, tenantID) {
+		return
+	}
+
+	var req CreateRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	scopes, err := rbac.ParseRights(req.Scopes)
+	if err != nil {
+		apierror.Abort(c, apperror.BadRequest(err.Error()))
+
+		return
+	}
+
+	if !ctrl.checkScopesAllowed(c, scopes) {
+		return
+	}
+
+	userID, _ := apihelper.GetSessionUserID(c)
+
+	value, key, err := ctrl.svc.Create(c.Request.Context(), apikeyService.CreateParams{
+		TenantID:  tenantID,
+		CreatedBy: userID,
+		Name:      req.Name,
+		Scopes:    scopes,
+		ExpiredAt: req.ExpiredAt,
+	})
+	if err != nil {
+		ctrl.handleError(c, "create", err)
+
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Info("api key created", field.Audit("api_key_created"), field.ID(key.ID),
+		field.String("key_tenant_id", tenantID.String()))
+
+	c.JSON(http.StatusCreated, CreatedAPIKey{APIKey: toAPIKey(*key), Key: value})
+}
+
+// ListAPIKeys godoc
+// @Summary List api keys
+// @Description List api keys of tenant (without secrets)
+// @Id ListAPIKeys
+// @Tags API keys
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Param tenant_id query string false "tenant id (required for users with `tenants:manage`, ignored for api keys)"
+// @Param sort query string false "created_at, name, expired_at, last_used_at; `-` prefix - desc" default(-created_at)
+// @Param filter query []string false "`field:op[:value]`, e.g. `revoked_at:null`" collectionFormat(multi)
+// @Param search query string false "full-text search by name and prefix: words, \"phrase\", prefix*, -word, OR"
+// @Param cursor query string false "page cursor from `Link` header"
+// @Param limit query int false "page size" default(100)
+// @Success 200 {array} APIKey
+// @Header 200 {string} Link "next and prev pages"
+// @Failure 400 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/api-keys [get]
+func (ctrl *Controller) list(c *gin.Context) {
+	tenantID, ok := ctrl.resolveTenant(c)
+	if !ok {
+		return
+	}
+
+	var req ListRequest
+	if !validation.BindQuery(c, &req) {
+		return
+	}
+
+	order, err := listSort.Parse(req.Sort)
+	if err != nil {
+		apierror.Abort(c, err)
+
+		return
+	}
+
+	where, err := listFilter.Parse(req.Filter...)
+	if err != nil {
+		apierror.Abort(c, err)
+
+		return
+	}
+
+	pageReq, err := ctrl.cursors.NewRequest(order, req.Cursor, req.Limit)
+	if err != nil {
+		apierror.Abort(c, err)
+
+		return
+	}
+
+	page, err := ctrl.svc.List(c.Request.Context(), tenantID, apikeyService.ListParams{
+		Filter: where,
+		Search: fulltext.Parse(req.Search),
+		Page:   pageReq,
+	})
+	if err != nil {
+		ctrl.handleError(c, "list", err)
+
+		return
+	}
+
+	result := make([]APIKey, 0, len(page.Items))
+	for _, hit := range page.Items {
+		k := toAPIKey(hit.APIKey)
+		k.Rank, k.Highlight = hit.Rank, hit.Highlight
+		result = append(result, k)
+	}
+
+	apihelper.SetPageLinks(c, page.Next, page.Prev)
+
+	apihelper.RenderList(c, http.StatusOK, result)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke api key
+// @Description Revoke api key of tenant
+// @Id RevokeAPIKey
+// @Tags API keys
+// @Param id path int true "api key id"
+// @Param tenant_id query string false "tenant id (required for users with `tenants:manage`, ignored for api keys)"
+// @Success 204
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/api-keys/{id} [delete]
+func (ctrl *Controller) revoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apperror.BadPathParam("id", err.Error()))
+
+		return
+	}
+
+	tenantID, ok := ctrl.resolveTenant(c)
+	if !ok {
+		return
+	}
+
+	if err = ctrl.svc.Revoke(c.Request.Context(), tenantID, id); err != nil {
+		ctrl.handleError(c, "revoke", err)
+
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Info("api key revoked", field.Audit("api_key_revoked"), field.ID(id),
+		field.String("key_tenant_id", tenantID.String()))
+
+	c.Status(http.StatusNoContent)
+}
+
+// checkScopesAllowed - protection from privilege escalation: key can't have more rights than its creator
+// and permissions on global objects (rbac.UserOnly).
+func (ctrl *Controller) checkScopesAllowed(c *gin.Context, scopes rbac.Rights) bool {
+	if userOnly := scopes & rbac.UserOnly; userOnly != 0 {
+		apierror.Abort(c, apperror.Forbidden("api key can't have permissions of users: "+userOnly.String()))
+
+		return false
+	}
+
+	var callerRights rbac.Rights
+
+	if key, err := apihelper.GetAPIToneFromGinCtx[*tables.APIKey](c); err == nil && key != nil {
+		callerRights = rbac.Rights(key.Rights)
+	} else if userID, ok := apihelper.GetSessionUserID(c); ok {
+		if callerRights, err = ctrl.rights.UserRights(c.Request.Context(), userID); err != nil {
+			ctrl.handleError(c, "user rights", err)
+
+			return false
+		}
+	}
+
+	if !callerRights.Has(scopes) {
+		apierror.Abort(c, apperror.Forbidden("api key scopes can't be wider than own rights: "+callerRights.String()))
+
+		return false
+	}
+
+	return true
+}
+
+func (ctrl *Controller) handleError(c *gin.Context, op string, err error) {
+	switch {
+	case errors.Is(err, apikeyService.ErrKeyNotFound):
+		apierror.Abort(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, apikeyService.ErrEmptyScopes), errors.Is(err, apikeyService.ErrExpiredInPast):
+		apierror.Abort(c, apperror.BadRequest(err.Error()))
+	default:
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("api keys controller: %s: %w", op, err)))
+	}
+}
+
+// resolveTenant - api key can manage only keys of own tenant, users must set tenant explicitly and have
+// `tenants:manage` permission, @see mw.AuthorizeTenant.
+func (ctrl *Controller) resolveTenant(c *gin.Context) (uuid.UUID, bool) {
+	if tenantID, ok := apihelper.GetTenantUUIDFromRequest(c); ok {
+		return tenantID, true
+	}
+
+	requested := mw.RequestTenant(c)
+	if requested == uuid.Nil {
+		apierror.Abort(c, apperror.BadQueryParam(apihelper.TenantIDParam, "tenant is required"))
+
+		return uuid.Nil, false
+	}
+
+	if !mw.AuthorizeTenant(c, ctrl.rights, requested) {
+		return uuid.Nil, false
+	}
+
+	return requested, true
+}
+
+func toAPIKey(k tables.APIKey) APIKey {
+	return APIKey{
+		ID:         k.ID,
+		TenantID:   k.TenantID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     rbac.Rights(k.Rights).Names(),
+		CreatedAt:  k.CreatedAt,
+		ExpiredAt:  k.ExpiredAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
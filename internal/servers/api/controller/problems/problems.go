@@ -0,0 +1,117 @@
+// Package problems - documentation of problem types (RFC-7807), @see apperror.Define.
+// Problem type URI of errors (`type` member) points to documentation page of this controller.
+package problems
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+type (
+	// Controller - problem types documentation http controller.
+	Controller struct{}
+
+	// Problem - problem type documentation.
+	Problem struct {
+		Type        string `json:"type" example:"/api/v1/problems/not_found"`
+		Code        string `json:"code" example:"not_found"`
+		Status      int    `json:"status" example:"404"`
+		Title       string `json:"title" example:"Not found"`
+		Description string `json:"description" example:"Requested resource doesn't exist"`
+	}
+)
+
+var pageTemplate = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<p><b>Type:</b> <code>{{.Type}}</code></p>
+<p><b>HTTP status:</b> {{.Status}}</p>
+<p>{{.Description}}</p>
+<p>Response is <code>application/problem+json</code> (RFC-7807) with members <code>type</code>, <code>title</code>,
+<code>status</code>, <code>detail</code>, <code>instance</code> and <code>request_id</code>.</p>
+<p><a href="../problems">All problem types</a></p>
+</body>
+</html>
+`))
+
+// New - constructor of problems Controller.
+func New() *Controller {
+	return &Controller{}
+}
+
+// Register - register public routes.
+func (ctrl *Controller) Register(public *gin.RouterGroup, _ *gin.RouterGroup) {
+	public.GET("/problems", ctrl.list)
+	public.GET("/problems/:code", ctrl.get)
+}
+
+// ListProblems godoc
+// @Summary List problem types
+// @Description List all problem types (RFC-7807), which can be returned by service
+// @Id ListProblems
+// @Tags Problems
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Success 200 {array} Problem
+// @Router /api/v1/problems [get]
+func (ctrl *Controller) list(c *gin.Context) {
+	types := apperror.ProblemTypes()
+
+	result := make([]Problem, 0, len(types))
+	for _, p := range types {
+		result = append(result, toProblem(p))
+	}
+
+	apihelper.RenderList(c, http.StatusOK, result)
+}
+
+// GetProblem godoc
+// @Summary Problem type documentation
+// @Description Documentation page of problem type (HTML), or JSON if it's requested by `Accept` header
+// @Id GetProblem
+// @Tags Problems
+// @Produce  html,json
+// @Param code path string true "problem type code"
+// @Success 200 {object} Problem
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/problems/{code} [get]
+func (ctrl *Controller) get(c *gin.Context) {
+	p, ok := apperror.LookupProblemType(c.Param("code"))
+	if !ok {
+		apierror.Abort(c, apperror.NotFound("unknown problem type"))
+
+		return
+	}
+
+	problem := toProblem(p)
+
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, problem)
+
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	if err := pageTemplate.Execute(c.Writer, problem); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func toProblem(p apperror.ProblemType) Problem {
+	return Problem{
+		Type:        apierror.TypePrefix + p.Code,
+		Code:        p.Code,
+		Status:      p.Status,
+		Title:       p.Title,
+		Description: p.Description,
+	}
+}
@@ -0,0 +1,51 @@
+package problems
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+)
+
+func TestController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	New().Register(e.Group("/api/v1"), nil)
+
+	do := func(path string, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("/api/v1/problems", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list []Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, len(apperror.ProblemTypes()))
+
+	w = do("/api/v1/problems/not_found", "text/html")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/html"))
+	assert.Contains(t, w.Body.String(), apperror.ErrNotFound.Title)
+
+	w = do("/api/v1/problems/not_found", "application/json")
+	var p Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "/api/v1/problems/not_found", p.Type)
+
+	w = do("/api/v1/problems/unknown", "application/json")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,337 @@
+// Package rbac - http handlers for roles and roles assignments management (admin API).
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+type (
+	// Service - rbac service, @see services/rbac.Service.
+	Service interface {
+		mw.PermissionChecker
+
+		ListRoles(ctx context.Context) ([]tables.Role, error)
+		CreateRole(ctx context.Context, name string, rights rbac.Rights) (*tables.Role, error)
+		UpdateRole(ctx context.Context, roleID int, name string, rights rbac.Rights) (*tables.Role, error)
+		DeleteRole(ctx context.Context, roleID int) error
+		UserRoles(ctx context.Context, userID int) ([]tables.Role, error)
+		AssignRole(ctx context.Context, userID int, roleID int) error
+		UnassignRole(ctx context.Context, userID int, roleID int) error
+	}
+
+	// Controller - rbac admin http controller.
+	Controller struct {
+		svc Service
+	}
+
+	// RoleRequest - create/update role request body.
+	RoleRequest struct {
+		Name        string   `json:"name" binding:"required,max=64"`
+		Permissions []string `json:"permissions" binding:"required,min=1,dive,required"`
+	}
+
+	// Role - role info for client.
+	Role struct {
+		ID          int      `json:"id"`
+		Name        string   `json:"name"`
+		Permissions []string `json:"permissions"`
+	}
+)
+
+// New - constructor of rbac Controller.
+func New(svc Service) *Controller {
+	return &Controller{svc: svc}
+}
+
+// Register - register admin routes, all of them require `roles:manage` permission.
+func (ctrl *Controller) Register(_ *gin.RouterGroup, private *gin.RouterGroup) {
+	admin := private.Group("", mw.RequirePermission(ctrl.svc, rbac.ManageRoles))
+
+	admin.GET("/permissions", ctrl.listPermissions)
+
+	admin.GET("/roles", ctrl.listRoles)
+	admin.POST("/roles", ctrl.createRole)
+	admin.PUT("/roles/:id", ctrl.updateRole)
+	admin.DELETE("/roles/:id", ctrl.deleteRole)
+
+	admin.GET("/users/:id/roles", ctrl.listUserRoles)
+	admin.PUT("/users/:id/roles/:role_id", ctrl.assignRole)
+	admin.DELETE("/users/:id/roles/:role_id", ctrl.unassignRole)
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List names of all known permissions
+// @Id ListPermissions
+// @Tags RBAC
+// @Produce  json
+// @Success 200 {array} string
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/permissions [get]
+func (ctrl *Controller) listPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, rbac.AllPermissions())
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List all roles
+// @Id ListRoles
+// @Tags RBAC
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Success 200 {array} Role
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/roles [get]
+func (ctrl *Controller) listRoles(c *gin.Context) {
+	roles, err := ctrl.svc.ListRoles(c.Request.Context())
+	if err != nil {
+		ctrl.internalError(c, "list roles", err)
+
+		return
+	}
+
+	apihelper.RenderList(c, http.StatusOK, toRoles(roles))
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Create new role with given permissions
+// @Id CreateRole
+// @Tags RBAC
+// @Accept  json
+// @Produce  json
+// @Param request body RoleRequest true "role"
+// @Success 201 {object} Role
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Failure 409 {object} apierror.APIError
+// @Router /api/v1/roles [post]
+func (ctrl *Controller) createRole(c *gin.Context) {
+	req, rights, ok := bindRoleRequest(c)
+	if !ok {
+		return
+	}
+
+	role, err := ctrl.svc.CreateRole(c.Request.Context(), req.Name, rights)
+	if err != nil {
+		ctrl.handleError(c, "create role", err)
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, toRole(*role))
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Update role name and permissions
+// @Id UpdateRole
+// @Tags RBAC
+// @Accept  json
+// @Produce  json
+// @Param id path int true "role id"
+// @Param request body RoleRequest true "role"
+// @Success 200 {object} Role
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Failure 409 {object} apierror.APIError
+// @Router /api/v1/roles/{id} [put]
+func (ctrl *Controller) updateRole(c *gin.Context) {
+	id, ok := intParam(c, "id")
+	if !ok {
+		return
+	}
+
+	req, rights, ok := bindRoleRequest(c)
+	if !ok {
+		return
+	}
+
+	role, err := ctrl.svc.UpdateRole(c.Request.Context(), id, req.Name, rights)
+	if err != nil {
+		ctrl.handleError(c, "update role", err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, toRole(*role))
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Delete role and all its assignments
+// @Id DeleteRole
+// @Tags RBAC
+// @Param id path int true "role id"
+// @Success 204
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/roles/{id} [delete]
+func (ctrl *Controller) deleteRole(c *gin.Context) {
+	id, ok := intParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.svc.DeleteRole(c.Request.Context(), id); err != nil {
+		ctrl.handleError(c, "delete role", err)
+
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserRoles godoc
+// @Summary List user roles
+// @Description List roles assigned to user
+// @Id ListUserRoles
+// @Tags RBAC
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Param id path int true "user id"
+// @Success 200 {array} Role
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/users/{id}/roles [get]
+func (ctrl *Controller) listUserRoles(c *gin.Context) {
+	userID, ok := intParam(c, "id")
+	if !ok {
+		return
+	}
+
+	roles, err := ctrl.svc.UserRoles(c.Request.Context(), userID)
+	if err != nil {
+		ctrl.internalError(c, "list user roles", err)
+
+		return
+	}
+
+	apihelper.RenderList(c, http.StatusOK, toRoles(roles))
+}
+
+// AssignRole godoc
+// @Summary Assign role
+// @Description Assign role to user
+// @Id AssignRole
+// @Tags RBAC
+// @Param id path int true "user id"
+// @Param role_id path int true "role id"
+// @Success 204
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/users/{id}/roles/{role_id} [put]
+func (ctrl *Controller) assignRole(c *gin.Context) {
+	ctrl.changeAssignment(c, "assign role", ctrl.svc.AssignRole)
+}
+
+// UnassignRole godoc
+// @Summary Unassign role
+// @Description Remove role from user
+// @Id UnassignRole
+// @Tags RBAC
+// @Param id path int true "user id"
+// @Param role_id path int true "role id"
+// @Success 204
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/users/{id}/roles/{role_id} [delete]
+func (ctrl *Controller) unassignRole(c *gin.Context) {
+	ctrl.changeAssignment(c, "unassign role", ctrl.svc.UnassignRole)
+}
+
+func (ctrl *Controller) changeAssignment(
+	c *gin.Context,
+	op string,
+	change func(ctx context.Context, userID int, roleID int) error,
+) {
+	userID, ok := intParam(c, "id")
+	if !ok {
+		return
+	}
+
+	roleID, ok := intParam(c, "role_id")
+	if !ok {
+		return
+	}
+
+	if err := change(c.Request.Context(), userID, roleID); err != nil {
+		ctrl.handleError(c, op, err)
+
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Info(op,
+		field.Audit("rbac_assignment"), field.Int("assignee_id", userID), field.Int("role_id", roleID))
+
+	c.Status(http.StatusNoContent)
+}
+
+func (ctrl *Controller) handleError(c *gin.Context, op string, err error) {
+	switch {
+	case errors.Is(err, rbac.ErrRoleNotFound), errors.Is(err, rbac.ErrUserNotFound):
+		apierror.Abort(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, rbac.ErrRoleExists):
+		apierror.Abort(c, apperror.Conflict(err.Error()))
+	case errors.Is(err, rbac.ErrEmptyRights):
+		apierror.Abort(c, apperror.BadRequest(err.Error()))
+	default:
+		ctrl.internalError(c, op, err)
+	}
+}
+
+func (ctrl *Controller) internalError(c *gin.Context, op string, err error) {
+	apierror.Abort(c, apperror.Internal(fmt.Errorf("rbac controller: %s: %w", op, err)))
+}
+
+func bindRoleRequest(c *gin.Context) (RoleRequest, rbac.Rights, bool) {
+	var req RoleRequest
+	if !validation.BindJSON(c, &req) {
+		return req, 0, false
+	}
+
+	rights, err := rbac.ParseRights(req.Permissions)
+	if err != nil {
+		apierror.Abort(c, apperror.BadRequest(err.Error()))
+
+		return req, 0, false
+	}
+
+	return req, rights, true
+}
+
+func intParam(c *gin.Context, name string) (int, bool) {
+	v, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		apierror.Abort(c, apperror.BadPathParam(name, err.Error()))
+
+		return 0, false
+	}
+
+	return v, true
+}
+
+func toRole(r tables.Role) Role {
+	return Role{ID: r.ID, Name: r.Name, Permissions: rbac.Rights(r.Rights).Names()}
+}
+
+func toRoles(roles []tables.Role) []Role {
+	result := make([]Role, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, toRole(r))
+	}
+
+	return result
+}
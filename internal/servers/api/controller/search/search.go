@@ -0,0 +1,165 @@
+// Package search - http handler of search in search cluster (Elasticsearch/OpenSearch).
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/filter"
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/daterange"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+	searchService "github.com/imperiuse/go-app-skeleton/internal/services/search"
+)
+
+type (
+	// Searcher - search cluster client, @see services/search.Client.
+	Searcher interface {
+		Search(ctx context.Context, q searchService.Query) (searchService.Result, error)
+	}
+
+	// Controller - search http controller.
+	Controller struct {
+		svc          Searcher
+		rights       mw.PermissionChecker
+		defaultIndex string
+	}
+
+	// Response - page of found documents.
+	Response struct {
+		Total int    `json:"total"`
+		Items []Item `json:"items"`
+	}
+
+	// Item - found document, Document is indexed model (@see SearchDocument of models).
+	Item struct {
+		Index    string          `json:"index"`
+		ID       string          `json:"id"`
+		Score    *float64        `json:"score,omitempty"`
+		Document json.RawMessage `json:"document"`
+	}
+)
+
+// indexes - searchable indexes and permission required for search in them.
+var indexes = map[string]rbac.Permission{
+	tables.APIKeyIndex: rbac.ManageAPIKeys,
+}
+
+// spec - search request rules, all indexes have `created_at` field.
+var spec = apihelper.SearchSpec{
+	Sort: sorting.NewSpec(map[string]string{
+		"score":      "_score",
+		"created_at": "created_at",
+	}, "id", "-score"),
+	Filter: filter.NewSpec(nil),
+	Dates:  daterange.New(daterange.Config{}),
+}
+
+// New - constructor of search Controller. defaultIndex is used if request has no `index` param.
+func New(svc Searcher, rights mw.PermissionChecker, defaultIndex string) *Controller {
+	return &Controller{svc: svc, rights: rights, defaultIndex: defaultIndex}
+}
+
+// Register - register routes.
+func (ctrl *Controller) Register(_ *gin.RouterGroup, private *gin.RouterGroup) {
+	private.GET("/search", ctrl.search)
+}
+
+// Search godoc
+// @Summary Search
+// @Description Search documents of tenant in search index. Index is eventually consistent with DB (about 1s).
+// @Id Search
+// @Tags Search
+// @Produce  json
+// @Param tenant_id query string true "tenant id (tenant of api key for api keys, users need `tenants:manage`)"
+// @Param index query string false "index, e.g. api_keys"
+// @Param search query string false "simple query string: words, \"phrase\", prefix*, -word, a | b"
+// @Param from_date query string false "created_at from (inclusive)"
+// @Param to_date query string false "created_at to (exclusive)"
+// @Param tz query string false "time zone of local dates, e.g. Europe/Berlin"
+// @Param sort query string false "score, created_at; `-` prefix - desc" default(-score)
+// @Param page query int false "page number" default(0)
+// @Param limit query int false "page size" default(10)
+// @Success 200 {object} Response
+// @Failure 400 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/search [get]
+func (ctrl *Controller) search(c *gin.Context) {
+	req, err := apihelper.ParseSearchRequest(c.Request.URL.Query(), spec)
+	if err != nil {
+		apierror.Abort(c, err)
+
+		return
+	}
+
+	index := req.Index
+	if index == "" {
+		index = ctrl.defaultIndex
+	}
+
+	perm, ok := indexes[index]
+	if !ok {
+		apierror.Abort(c, apperror.BadQueryParam(apihelper.IndexNameParam, "unknown index"))
+
+		return
+	}
+
+	// tenant_id is validated by binding, parsed for canonical (lower case) form of indexed documents.
+	tenantID, _ := uuid.Parse(req.TenantID)
+
+	// api key can search only in own tenant, user - in any tenant with `tenants:manage` permission.
+	if !mw.AuthorizeTenant(c, ctrl.rights, tenantID) {
+		return
+	}
+
+	// permission depends on index, so it's checked here instead of route middleware.
+	if mw.RequirePermission(ctrl.rights, perm)(c); c.IsAborted() {
+		return
+	}
+
+	res, err := ctrl.svc.Search(c.Request.Context(), searchService.Query{
+		Index:    index,
+		TenantID: tenantID.String(),
+		Text:     req.SearchPattern,
+		From:     req.Dates.From,
+		To:       req.Dates.To,
+		Sort:     req.Order,
+		Offset:   req.Offset(),
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		ctrl.handleError(c, err)
+
+		return
+	}
+
+	items := make([]Item, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		items = append(items, Item{Index: h.Index, ID: h.ID, Score: h.Score, Document: h.Source})
+	}
+
+	c.JSON(http.StatusOK, Response{Total: res.Total, Items: items})
+}
+
+func (ctrl *Controller) handleError(c *gin.Context, err error) {
+	var respErr *searchService.ResponseError
+	if errors.As(err, &respErr) && respErr.Type == "index_not_found_exception" {
+		apierror.Abort(c, apperror.NotFound("index not found"))
+
+		return
+	}
+
+	apierror.Abort(c, apperror.Internal(fmt.Errorf("search controller: %w", err)))
+}
@@ -0,0 +1,276 @@
+// Package session - http handlers for user sessions management.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+	sessionService "github.com/imperiuse/go-app-skeleton/internal/services/session"
+)
+
+const ipParam apihelper.QueryParamName = "ip"
+
+type (
+	// Service - session service, @see services/session.Service.
+	Service interface {
+		Login(ctx context.Context, email string, password string, ip string, userAgent string) (
+			string, *tables.Session, error)
+		ListActive(ctx context.Context, userID int) ([]tables.Session, error)
+		Revoke(ctx context.Context, userID int, sessionID int) error
+		RevokeOthers(ctx context.Context, userID int, exceptSessionID int, ip string) (int64, error)
+	}
+
+	// Controller - sessions http controller.
+	Controller struct {
+		svc    Service
+		cookie mw.SessionCookie
+	}
+
+	// LoginRequest - login request body.
+	LoginRequest struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required,max=256"`
+	}
+
+	// Session - session info for client.
+	Session struct {
+		ID        int       `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		ExpiredAt time.Time `json:"expired_at"`
+		IP        string    `json:"ip"`
+		UserAgent string    `json:"user_agent"`
+		Current   bool      `json:"current"`
+	}
+
+	// RevokeResult - result of bulk revoke.
+	RevokeResult struct {
+		Revoked int64 `json:"revoked"`
+	}
+)
+
+// New - constructor of sessions Controller.
+func New(svc Service, cookie mw.SessionCookie) *Controller {
+	return &Controller{svc: svc, cookie: cookie}
+}
+
+// Register - register routes. Login is public, other routes require session.
+func (ctrl *Controller) Register(public *gin.RouterGroup, private *gin.RouterGroup) {
+	public.POST("/sessions", ctrl.login)
+
+	private.GET("/sessions", ctrl.list)
+	private.DELETE("/sessions", ctrl.revokeOthers)
+	private.DELETE("/sessions/current", ctrl.logout)
+	private.DELETE("/sessions/:id", ctrl.revoke)
+}
+
+// Login godoc
+// @Summary Login
+// @Description Check credentials, issue new session and set session cookie
+// @Id Login
+// @Tags Sessions
+// @Accept  json
+// @Produce  json
+// @Param request body LoginRequest true "credentials"
+// @Success 201 {object} Session
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 401 {object} apierror.APIError
+// @Router /api/v1/sessions [post]
+func (ctrl *Controller) login(c *gin.Context) {
+	var req LoginRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	token, s, err := ctrl.svc.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, sessionService.ErrInvalidCredentials) {
+			apierror.Abort(c, apperror.Unauthorized(err.Error()))
+
+			return
+		}
+
+		ctrl.internalError(c, "login", err)
+
+		return
+	}
+
+	ctrl.cookie.Set(c, token, s.ExpiredAt)
+
+	c.JSON(http.StatusCreated, toSession(*s, s.ID))
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List active sessions (devices/IPs) of current user
+// @Id ListSessions
+// @Tags Sessions
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Success 200 {array} Session
+// @Failure 401 {object} apierror.APIError
+// @Router /api/v1/sessions [get]
+func (ctrl *Controller) list(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := ctrl.svc.ListActive(c.Request.Context(), current.UserID)
+	if err != nil {
+		ctrl.internalError(c, "list", err)
+
+		return
+	}
+
+	result := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, toSession(s, current.ID))
+	}
+
+	apihelper.RenderList(c, http.StatusOK, result)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke current session and clear session cookie
+// @Id Logout
+// @Tags Sessions
+// @Success 204
+// @Failure 401 {object} apierror.APIError
+// @Router /api/v1/sessions/current [delete]
+func (ctrl *Controller) logout(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.svc.Revoke(c.Request.Context(), current.UserID, current.ID); err != nil &&
+		!errors.Is(err, sessionService.ErrSessionNotFound) {
+		ctrl.internalError(c, "logout", err)
+
+		return
+	}
+
+	ctrl.cookie.Clear(c)
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Revoke one session of current user
+// @Id RevokeSession
+// @Tags Sessions
+// @Param id path int true "session id"
+// @Success 204
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/sessions/{id} [delete]
+func (ctrl *Controller) revoke(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apperror.BadPathParam("id", err.Error()))
+
+		return
+	}
+
+	if err = ctrl.svc.Revoke(c.Request.Context(), current.UserID, id); err != nil {
+		if errors.Is(err, sessionService.ErrSessionNotFound) {
+			apierror.Abort(c, apperror.NotFound(err.Error()))
+
+			return
+		}
+
+		ctrl.internalError(c, "revoke", err)
+
+		return
+	}
+
+	if id == current.ID {
+		ctrl.cookie.Clear(c)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke other sessions
+// @Description Revoke all sessions of current user except current one (optionally only from given ip)
+// @Id RevokeOtherSessions
+// @Tags Sessions
+// @Produce  json
+// @Param ip query string false "revoke only sessions from this ip"
+// @Success 200 {object} RevokeResult
+// @Failure 400 {object} apierror.APIError
+// @Failure 401 {object} apierror.APIError
+// @Router /api/v1/sessions [delete]
+func (ctrl *Controller) revokeOthers(c *gin.Context) {
+	current, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	ip := c.Query(ipParam)
+	if ip != "" {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			apierror.Abort(c, apperror.BadQueryParam(ipParam, "invalid ip address"))
+
+			return
+		}
+
+		ip = addr.String()
+	}
+
+	n, err := ctrl.svc.RevokeOthers(c.Request.Context(), current.UserID, current.ID, ip)
+	if err != nil {
+		ctrl.internalError(c, "revoke others", err)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeResult{Revoked: n})
+}
+
+func (ctrl *Controller) internalError(c *gin.Context, op string, err error) {
+	apierror.Abort(c, apperror.Internal(fmt.Errorf("sessions controller: %s: %w", op, err)))
+}
+
+// currentSession - session of requesting user, it's absent if auth is disabled.
+func currentSession(c *gin.Context) (*tables.Session, bool) {
+	current, ok := apihelper.GetSessionFromRequest(c)
+	if !ok {
+		apierror.Abort(c, apperror.Unauthorized("session is required"))
+	}
+
+	return current, ok
+}
+
+func toSession(s tables.Session, currentID int) Session {
+	return Session{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt,
+		ExpiredAt: s.ExpiredAt,
+		IP:        s.IP,
+		UserAgent: s.UserAgent,
+		Current:   s.ID == currentID,
+	}
+}
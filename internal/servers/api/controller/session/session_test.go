@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+)
+
+// fakeService - sessions service which records ip of RevokeOthers.
+type fakeService struct {
+	Service
+	revokedIP *string
+}
+
+func (f fakeService) RevokeOthers(_ context.Context, _ int, _ int, ip string) (int64, error) {
+	*f.revokedIP = ip
+
+	return 1, nil
+}
+
+func TestController_RevokeOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var revokedIP string
+
+	e := gin.New()
+	e.Use(mw.ErrorMiddleware(), func(c *gin.Context) {
+		apihelper.SetSessionForRequest(c, &tables.Session{ID: 1, UserID: 2})
+	})
+
+	g := e.Group("/api/v1")
+	New(fakeService{revokedIP: &revokedIP}, mw.SessionCookie{}).Register(g, g)
+
+	do := func(query string) int {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/sessions"+query, nil))
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do("?ip=not-an-ip"))
+	assert.Empty(t, revokedIP, "malformed ip isn't queried")
+
+	assert.Equal(t, http.StatusOK, do("?ip=2001:DB8::1"))
+	assert.Equal(t, "2001:db8::1", revokedIP)
+}
@@ -0,0 +1,396 @@
+// Package tenant - http handlers for tenants management (admin API).
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+	tenantService "github.com/imperiuse/go-app-skeleton/internal/services/tenant"
+)
+
+type (
+	// Service - tenant service, @see services/tenant.Service.
+	Service interface {
+		Create(ctx context.Context, name string, settings tables.TenantSettings) (*tables.Tenant, error)
+		List(ctx context.Context) ([]tables.Tenant, error)
+		Get(ctx context.Context, id uuid.UUID) (*tables.Tenant, error)
+		UpdateSettings(
+			ctx context.Context,
+			id uuid.UUID,
+			settings tables.TenantSettings,
+			version int64,
+		) (*tables.Tenant, error)
+		Suspend(ctx context.Context, id uuid.UUID) (*tables.Tenant, error)
+		Resume(ctx context.Context, id uuid.UUID) (*tables.Tenant, error)
+		Delete(ctx context.Context, id uuid.UUID, version int64) error
+	}
+
+	// Controller - tenants admin http controller.
+	Controller struct {
+		svc    Service
+		rights mw.PermissionChecker
+	}
+
+	// CreateRequest - create tenant request body.
+	CreateRequest struct {
+		Name     string   `json:"name" binding:"required,max=128"`
+		Settings Settings `json:"settings"`
+	}
+
+	// Settings - per-tenant settings, empty values mean service defaults.
+	Settings struct {
+		TimeZone string           `json:"time_zone,omitempty" binding:"max=64" example:"Europe/Berlin"`
+		Locale   string           `json:"locale,omitempty" binding:"max=35" example:"en-US"`
+		Features map[string]bool  `json:"features,omitempty" binding:"max=64"`
+		Limits   map[string]int64 `json:"limits,omitempty" binding:"max=64,dive,min=0"`
+	}
+
+	// Tenant - tenant info for client.
+	Tenant struct {
+		ID          uuid.UUID  `json:"id"`
+		Name        string     `json:"name"`
+		Status      string     `json:"status" enums:"active,suspended,deleted"`
+		Version     int64      `json:"version"` // incremented by each change, it's `ETag` of tenant.
+		Settings    Settings   `json:"settings"`
+		CreatedAt   time.Time  `json:"created_at"`
+		SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+		DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	}
+)
+
+// New - constructor of tenants Controller.
+func New(svc Service, rights mw.PermissionChecker) *Controller {
+	return &Controller{svc: svc, rights: rights}
+}
+
+// Register - register admin routes, all of them require `tenants:manage` permission, which api keys never have.
+func (ctrl *Controller) Register(_ *gin.RouterGroup, private *gin.RouterGroup) {
+	g := private.Group("/tenants", mw.RequirePermission(ctrl.rights, rbac.ManageTenants))
+
+	g.GET("", ctrl.list)
+	g.POST("", ctrl.create)
+	g.GET("/:id", ctrl.get)
+	g.PUT("/:id/settings", ctrl.updateSettings)
+	g.POST("/:id/suspend", ctrl.suspend)
+	g.POST("/:id/resume", ctrl.resume)
+	g.DELETE("/:id", ctrl.delete)
+}
+
+// ListTenants godoc
+// @Summary List tenants
+// @Description List all tenants (including suspended and deleted)
+// @Id ListTenants
+// @Tags Tenants
+// @Produce  json,application/x-ndjson,text/csv,application/msgpack
+// @Success 200 {array} Tenant
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/tenants [get]
+func (ctrl *Controller) list(c *gin.Context) {
+	tenants, err := ctrl.svc.List(c.Request.Context())
+	if err != nil {
+		ctrl.handleError(c, "list", err)
+
+		return
+	}
+
+	result := make([]Tenant, 0, len(tenants))
+	for _, t := range tenants {
+		result = append(result, toTenant(t))
+	}
+
+	apihelper.RenderList(c, http.StatusOK, result)
+}
+
+// CreateTenant godoc
+// @Summary Create tenant
+// @Description Register new active tenant
+// @Id CreateTenant
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param request body CreateRequest true "tenant"
+// @Success 201 {object} Tenant
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/tenants [post]
+func (ctrl *Controller) create(c *gin.Context) {
+	var req CreateRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	t, err := ctrl.svc.Create(c.Request.Context(), req.Name, req.Settings.toTable())
+	if err != nil {
+		ctrl.handleError(c, "create", err)
+
+		return
+	}
+
+	ctrl.audit(c, "tenant_created", t.ID)
+
+	setETag(c, t)
+	c.JSON(http.StatusCreated, toTenant(*t))
+}
+
+// GetTenant godoc
+// @Summary Get tenant
+// @Description Get tenant status and settings. Response has `ETag` (version of tenant) and `Last-Modified` headers,
+// @Description 304 is returned for `If-None-Match`/`If-Modified-Since` if tenant isn't modified.
+// @Id GetTenant
+// @Tags Tenants
+// @Produce  json
+// @Param id path string true "tenant id"
+// @Param If-None-Match header string false "etag of cached tenant"
+// @Param If-Modified-Since header string false "last modified of cached tenant"
+// @Success 200 {object} Tenant
+// @Header 200 {string} ETag "version of tenant"
+// @Success 304
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/tenants/{id} [get]
+func (ctrl *Controller) get(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	t, err := ctrl.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		ctrl.handleError(c, "get", err)
+
+		return
+	}
+
+	if apihelper.NotModified(c, apihelper.VersionETag(t.Version), t.UpdatedAt) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toTenant(*t))
+}
+
+// UpdateTenantSettings godoc
+// @Summary Update tenant settings
+// @Description Replace settings of tenant (time zone, locale, feature toggles, limits).
+// @Description With `If-Match` header settings are replaced only if tenant isn't modified since (412 otherwise).
+// @Id UpdateTenantSettings
+// @Tags Tenants
+// @Accept  json
+// @Produce  json
+// @Param id path string true "tenant id"
+// @Param If-Match header string false "etag of tenant"
+// @Param request body Settings true "settings"
+// @Success 200 {object} Tenant
+// @Failure 400 {object} apierror.APIError
+// @Failure 422 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Failure 412 {object} apierror.APIError
+// @Router /api/v1/tenants/{id}/settings [put]
+func (ctrl *Controller) updateSettings(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	var req Settings
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	version, ok := ctrl.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
+
+	t, err := ctrl.svc.UpdateSettings(c.Request.Context(), id, req.toTable(), version)
+	if err != nil {
+		ctrl.handleError(c, "update settings", err)
+
+		return
+	}
+
+	ctrl.audit(c, "tenant_settings_updated", id)
+
+	setETag(c, t)
+	c.JSON(http.StatusOK, toTenant(*t))
+}
+
+// SuspendTenant godoc
+// @Summary Suspend tenant
+// @Description Suspend tenant: all requests of tenant are rejected with `tenant_suspended` problem until resume
+// @Id SuspendTenant
+// @Tags Tenants
+// @Produce  json
+// @Param id path string true "tenant id"
+// @Success 200 {object} Tenant
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/tenants/{id}/suspend [post]
+func (ctrl *Controller) suspend(c *gin.Context) {
+	ctrl.changeStatus(c, "tenant_suspended", ctrl.svc.Suspend)
+}
+
+// ResumeTenant godoc
+// @Summary Resume tenant
+// @Description Make suspended tenant active
+// @Id ResumeTenant
+// @Tags Tenants
+// @Produce  json
+// @Param id path string true "tenant id"
+// @Success 200 {object} Tenant
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Router /api/v1/tenants/{id}/resume [post]
+func (ctrl *Controller) resume(c *gin.Context) {
+	ctrl.changeStatus(c, "tenant_resumed", ctrl.svc.Resume)
+}
+
+// DeleteTenant godoc
+// @Summary Delete tenant
+// @Description Soft delete tenant: requests of tenant are rejected forever, data is kept.
+// @Description With `If-Match` header tenant is deleted only if it isn't modified since (412 otherwise).
+// @Id DeleteTenant
+// @Tags Tenants
+// @Param id path string true "tenant id"
+// @Param If-Match header string false "etag of tenant"
+// @Success 204
+// @Failure 400 {object} apierror.APIError
+// @Failure 404 {object} apierror.APIError
+// @Failure 412 {object} apierror.APIError
+// @Router /api/v1/tenants/{id} [delete]
+func (ctrl *Controller) delete(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	version, ok := ctrl.ifMatchVersion(c, id)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.svc.Delete(c.Request.Context(), id, version); err != nil {
+		ctrl.handleError(c, "delete", err)
+
+		return
+	}
+
+	ctrl.audit(c, "tenant_deleted", id)
+
+	c.Status(http.StatusNoContent)
+}
+
+func (ctrl *Controller) changeStatus(
+	c *gin.Context,
+	event string,
+	change func(ctx context.Context, id uuid.UUID) (*tables.Tenant, error),
+) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	t, err := change(c.Request.Context(), id)
+	if err != nil {
+		ctrl.handleError(c, event, err)
+
+		return
+	}
+
+	ctrl.audit(c, event, id)
+
+	setETag(c, t)
+	c.JSON(http.StatusOK, toTenant(*t))
+}
+
+// ifMatchVersion - expected version of tenant by `If-Match` header, 0 (any) without header.
+func (ctrl *Controller) ifMatchVersion(c *gin.Context, id uuid.UUID) (int64, bool) {
+	if !apihelper.HasIfMatch(c) {
+		return 0, true
+	}
+
+	t, err := ctrl.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		ctrl.handleError(c, "get", err)
+
+		return 0, false
+	}
+
+	if !apihelper.CheckIfMatch(c, apihelper.VersionETag(t.Version)) {
+		return 0, false
+	}
+
+	return t.Version, true
+}
+
+func (ctrl *Controller) audit(c *gin.Context, event string, id uuid.UUID) {
+	logger.FromContext(c.Request.Context()).Info("tenant changed", field.Audit(event),
+		field.String("target_tenant_id", id.String()))
+}
+
+func (ctrl *Controller) handleError(c *gin.Context, op string, err error) {
+	switch {
+	case errors.Is(err, tenantService.ErrTenantNotFound):
+		apierror.Abort(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, tenantService.ErrInvalidSettings):
+		apierror.Abort(c, apperror.BadRequest(err.Error()))
+	case errors.Is(err, tenantService.ErrVersionMismatch):
+		apierror.Abort(c, apperror.ErrPreconditionFailed.WithDetail(err.Error()))
+	default:
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("tenants controller: %s: %w", op, err)))
+	}
+}
+
+func idParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apperror.BadPathParam("id", "must be uuid"))
+
+		return uuid.Nil, false
+	}
+
+	mw.LogTenant(c, id)
+
+	return id, true
+}
+
+func setETag(c *gin.Context, t *tables.Tenant) {
+	c.Header(apihelper.ETagHeader, apihelper.VersionETag(t.Version).String())
+}
+
+func (s Settings) toTable() tables.TenantSettings {
+	return tables.TenantSettings{TimeZone: s.TimeZone, Locale: s.Locale, Features: s.Features, Limits: s.Limits}
+}
+
+func toTenant(t tables.Tenant) Tenant {
+	return Tenant{
+		ID:      t.ID,
+		Name:    t.Name,
+		Status:  t.Status,
+		Version: t.Version,
+		Settings: Settings{
+			TimeZone: t.Settings.TimeZone,
+			Locale:   t.Settings.Locale,
+			Features: t.Settings.Features,
+			Limits:   t.Settings.Limits,
+		},
+		CreatedAt:   t.CreatedAt,
+		SuspendedAt: t.SuspendedAt,
+		DeletedAt:   t.DeletedAt,
+	}
+}
@@ -0,0 +1,179 @@
+// Package usage - http handlers of tenant usage and quotas.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/daterange"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	mw "github.com/imperiuse/go-app-skeleton/internal/servers/api/middleware"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+	"github.com/imperiuse/go-app-skeleton/internal/services/metering"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+// maxRange - max range of usage history, last maxRange by default.
+const maxRange = 93 * 24 * time.Hour
+
+type (
+	// Service - metering service, @see services/metering.Service.
+	Service interface {
+		History(
+			ctx context.Context,
+			tenantID uuid.UUID,
+			metric metering.Metric,
+			period metering.Period,
+			from time.Time,
+			to time.Time,
+		) ([]tables.UsageCounter, error)
+		CurrentUsage(ctx context.Context, tenantID uuid.UUID) ([]metering.Usage, error)
+	}
+
+	// Controller - usage http controller.
+	Controller struct {
+		svc    Service
+		rights mw.PermissionChecker
+		cache  gin.HandlerFunc // optional response cache of reports, @see middleware.CacheMiddleware.
+		dates  *daterange.Parser
+	}
+
+	// Request - query params of usage report.
+	Request struct {
+		Metric   string `form:"metric" binding:"omitempty,oneof=requests reports storage_bytes"`
+		Period   string `form:"period,default=day" binding:"oneof=hour day"`
+		FromDate string `form:"from_date" binding:"max=64"` // @see daterange package for format.
+		ToDate   string `form:"to_date" binding:"max=64"`
+		TZ       string `form:"tz" binding:"max=64"`
+	}
+
+	// Report - usage of tenant by periods and current usage of quotas.
+	Report struct {
+		TenantID uuid.UUID `json:"tenant_id"`
+		Period   string    `json:"period" enums:"hour,day"`
+		From     time.Time `json:"from"`
+		To       time.Time `json:"to"`
+		Usage    []Bucket  `json:"usage"`
+		Quotas   []Quota   `json:"quotas"`
+	}
+
+	// Bucket - usage of metric in period starting at Start (UTC). Usage of last seconds may be not included yet.
+	Bucket struct {
+		Metric string    `json:"metric" enums:"requests,reports,storage_bytes"`
+		Start  time.Time `json:"start"`
+		Value  int64     `json:"value"`
+	}
+
+	// Quota - quota of tenant and its usage in current period, zero limit - no limit.
+	Quota struct {
+		Metric  string    `json:"metric" enums:"requests,reports,storage_bytes"`
+		Period  string    `json:"period" enums:"hour,day"`
+		Soft    int64     `json:"soft_limit"`
+		Hard    int64     `json:"hard_limit"`
+		Used    int64     `json:"used"`
+		ResetAt time.Time `json:"reset_at"`
+	}
+)
+
+// New - constructor of usage Controller. Reports are cached by cache middleware, if it isn't nil.
+func New(svc Service, rights mw.PermissionChecker, cache gin.HandlerFunc) *Controller {
+	return &Controller{
+		svc:    svc,
+		rights: rights,
+		cache:  cache,
+		dates:  daterange.New(daterange.Config{MaxRange: maxRange}),
+	}
+}
+
+// Register - register routes, all of them require `usage:view` permission.
+func (ctrl *Controller) Register(_ *gin.RouterGroup, private *gin.RouterGroup) {
+	handlers := []gin.HandlerFunc{mw.RequirePermission(ctrl.rights, rbac.ViewUsage), mw.RequireTenant(ctrl.rights)}
+	if ctrl.cache != nil { // after permission and tenant checks: cached reports are shared by clients of tenant.
+		handlers = append(handlers, ctrl.cache)
+	}
+
+	private.GET("/usage", append(handlers, ctrl.usage)...)
+}
+
+// GetUsage godoc
+// @Summary Usage of tenant
+// @Description Usage of tenant aggregated by hour or day and current usage of quotas.
+// @Description Usage is flushed periodically, so usage of last seconds may be not included.
+// @Id GetUsage
+// @Tags Usage
+// @Produce  json
+// @Param tenant_id query string false "tenant id (required for users with `tenants:manage`, ignored for api keys)"
+// @Param metric query string false "metric, all by default" Enums(requests, reports, storage_bytes)
+// @Param period query string false "aggregation period" Enums(hour, day) default(day)
+// @Param from_date query string false "from (inclusive), last 93 days by default"
+// @Param to_date query string false "to (exclusive)"
+// @Param tz query string false "time zone of local dates, e.g. Europe/Berlin"
+// @Success 200 {object} Report
+// @Failure 400 {object} apierror.APIError
+// @Failure 403 {object} apierror.APIError
+// @Router /api/v1/usage [get]
+func (ctrl *Controller) usage(c *gin.Context) {
+	tenantID := mw.RequestTenant(c) // authorized by mw.RequireTenant.
+
+	var req Request
+	if !validation.BindQuery(c, &req) {
+		return
+	}
+
+	dates, err := ctrl.dates.Parse(req.FromDate, req.ToDate, req.TZ)
+	if err != nil {
+		apierror.Abort(c, err)
+
+		return
+	}
+
+	ctx := c.Request.Context()
+	period := metering.Period(req.Period)
+
+	counters, err := ctrl.svc.History(ctx, tenantID, metering.Metric(req.Metric), period, dates.From, dates.To)
+	if err != nil {
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("usage controller: history: %w", err)))
+
+		return
+	}
+
+	usages, err := ctrl.svc.CurrentUsage(ctx, tenantID)
+	if err != nil {
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("usage controller: current usage: %w", err)))
+
+		return
+	}
+
+	report := Report{
+		TenantID: tenantID,
+		Period:   req.Period,
+		From:     dates.From,
+		To:       dates.To,
+		Usage:    make([]Bucket, 0, len(counters)),
+		Quotas:   make([]Quota, 0, len(usages)),
+	}
+
+	for _, uc := range counters {
+		report.Usage = append(report.Usage, Bucket{Metric: uc.Metric, Start: uc.Bucket, Value: uc.Value})
+	}
+
+	for _, u := range usages {
+		report.Quotas = append(report.Quotas, Quota{
+			Metric:  string(u.Metric),
+			Period:  string(u.Period),
+			Soft:    u.Soft,
+			Hard:    u.Hard,
+			Used:    u.Used,
+			ResetAt: u.ResetAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+)
+
+// APIKeyValidator - validate api key, @see services/apikey.Service.
+type APIKeyValidator interface {
+	Validate(ctx context.Context, key string) (*tables.APIKey, error)
+}
+
+// AuthMiddleware - authenticate machine clients by api key (`X-AUTH-TOKEN` header) and users by session cookie.
+// If header is present, session cookie is not checked at all.
+func AuthMiddleware(sessions SessionValidator, cookie SessionCookie, apiKeys APIKeyValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticated := false
+		if key := c.GetHeader(apihelper.AuthTokenHeader); key != "" {
+			authenticated = authenticateAPIKey(c, apiKeys, key)
+		} else {
+			authenticated = authenticateSession(c, sessions, cookie)
+		}
+
+		if !authenticated {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey - validate api key, abort request with 401 if it's not valid.
+// Tenant of request is always the owner of the key.
+func authenticateAPIKey(c *gin.Context, v APIKeyValidator, key string) bool {
+	apiKey, err := v.Validate(c.Request.Context(), key)
+	if err != nil {
+		abortUnauthorized(c, "api key is invalid, expired or revoked")
+
+		return false
+	}
+
+	apihelper.SetAPITokenToGinCtx(c, apiKey)
+	apihelper.SetTenantUUIDForRequest(c, apiKey.TenantID)
+	addLogFields(c, field.Int("api_key_id", apiKey.ID), field.TenantID(apiKey.TenantID.String()))
+
+	return true
+}
+
+// getAPIKey - api key of request, if request was authenticated by api key.
+func getAPIKey(c *gin.Context) (*tables.APIKey, bool) {
+	key, err := apihelper.GetAPIToneFromGinCtx[*tables.APIKey](c)
+
+	return key, err == nil && key != nil
+}
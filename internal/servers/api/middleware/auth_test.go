@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+var errInvalid = errors.New("invalid")
+
+type (
+	fakeSessions map[string]*tables.Session
+	fakeAPIKeys  map[string]*tables.APIKey
+)
+
+func (f fakeSessions) Validate(_ context.Context, token string) (*tables.Session, bool, error) {
+	if s, ok := f[token]; ok {
+		return s, false, nil
+	}
+
+	return nil, false, errInvalid
+}
+
+func (f fakeAPIKeys) Validate(_ context.Context, key string) (*tables.APIKey, error) {
+	if k, ok := f[key]; ok {
+		return k, nil
+	}
+
+	return nil, errInvalid
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantID := uuid.New()
+	sessions := fakeSessions{"good": {ID: 1, UserID: 7}}
+	apiKeys := fakeAPIKeys{
+		"rsk_good":  {ID: 2, TenantID: tenantID, Rights: int16(rbac.ReadReports)},
+		"rsk_roles": {ID: 3, TenantID: tenantID, Rights: int16(rbac.ReadReports | rbac.ManageRoles)},
+	}
+
+	e := gin.New()
+	e.Use(AuthMiddleware(sessions, SessionCookie{}, apiKeys))
+	e.GET("/me", func(c *gin.Context) {
+		tID, _ := apihelper.GetTenantUUIDFromRequest(c)
+		userID, _ := apihelper.GetSessionUserID(c)
+		c.JSON(http.StatusOK, gin.H{"tenant": tID.String(), "user": userID})
+	})
+	e.GET("/reports", RequirePermission(fakeChecker(0), rbac.ReadReports),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.GET("/roles", RequirePermission(fakeChecker(0), rbac.ManageRoles),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(path string, header string, cookie string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			r.Header.Set(apihelper.AuthTokenHeader, header)
+		}
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: defaultSessionCookieName, Value: cookie})
+		}
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do("/me", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("/me", "", "bad").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("/me", "rsk_bad", "good").Code, "header has priority over cookie")
+
+	w := do("/me", "", "good")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"tenant":"00000000-0000-0000-0000-000000000000","user":7}`, w.Body.String())
+
+	w = do("/me", "rsk_good", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"tenant":"`+tenantID.String()+`","user":0}`, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, do("/reports", "rsk_good", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("/roles", "rsk_good", "").Code)
+	assert.Equal(t, http.StatusForbidden, do("/roles", "rsk_roles", "").Code, "roles are managed only by users")
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+	"github.com/imperiuse/go-app-skeleton/internal/services/cache"
+)
+
+const (
+	// CacheStatusHeader - response header, `HIT` if response is served from cache, else `MISS`.
+	CacheStatusHeader = "X-Cache"
+	// AgeHeader - response header, seconds since cached response is computed.
+	AgeHeader = "Age"
+
+	cacheControlHeader = "Cache-Control"
+
+	cacheHit       = "hit"
+	cacheMiss      = "miss"
+	cacheCoalesced = "coalesced"
+	cacheBypass    = "bypass"
+)
+
+// CacheRule - caching settings of route.
+type CacheRule struct {
+	TTL  time.Duration // default ttl, `max-age`/`s-maxage` of response overrides it.
+	Tags []string      // entries are invalidated by these tags and tenant tag (cache.TenantTag).
+}
+
+// CacheMiddleware - cache successful GET responses of route in store. Key of response is path, normalized query
+// (sorted params), `Accept` header and tenant of request, so middleware must be registered on route after permission
+// checks and only for responses which don't depend on anything else (e.g. user). Concurrent misses of key are
+// coalesced: handler is called once and its response is shared.
+// `Cache-Control` is honored: `no-store` of request bypasses cache, `no-cache` (or `max-age=0`) of request skips
+// lookup, `no-store`/`no-cache`/`private` of response aren't stored.
+func CacheMiddleware(store cache.Store, rule CacheRule) gin.HandlerFunc {
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+
+			return
+		}
+
+		route := c.FullPath()
+		directives := parseCacheControl(c.Request.Header.Get(cacheControlHeader))
+
+		if _, ok := directives["no-store"]; ok {
+			metrics.ResponseCacheInc(route, cacheBypass)
+			c.Next()
+
+			return
+		}
+
+		tenantTag := cache.TenantTag(RequestTenant(c))
+		key := tenantTag + " " + c.Request.URL.Path + "?" + normalizedQuery(c.Request.URL.Query()) +
+			" " + c.GetHeader("Accept") // media type of response is negotiated, @see apihelper.RenderList.
+
+		if !isRevalidation(directives) {
+			if e, ok := store.Get(key); ok {
+				metrics.ResponseCacheInc(route, cacheHit)
+				writeCached(c, e)
+
+				return
+			}
+		}
+
+		computeAndStore := func() *cache.Entry {
+			e := computeResponse(c, rule, tenantTag)
+			if e != nil {
+				store.Set(key, e)
+			}
+
+			return e
+		}
+
+		if isRevalidation(directives) {
+			metrics.ResponseCacheInc(route, cacheMiss)
+			computeAndStore()
+
+			return
+		}
+
+		leader := false
+		v, _, _ := group.Do(key, func() (any, error) {
+			leader = true
+			metrics.ResponseCacheInc(route, cacheMiss)
+
+			return computeAndStore(), nil
+		})
+
+		if leader {
+			return
+		}
+
+		if e := v.(*cache.Entry); e != nil { //nolint:forcetypeassert // only entries are computed.
+			metrics.ResponseCacheInc(route, cacheCoalesced)
+			writeCached(c, e)
+
+			return
+		}
+
+		// response of leader isn't cacheable, e.g. error, so it isn't shared.
+		metrics.ResponseCacheInc(route, cacheMiss)
+		c.Next()
+	}
+}
+
+// computeResponse - call handler and record its response, nil if response isn't cacheable.
+func computeResponse(c *gin.Context, rule CacheRule, tenantTag string) *cache.Entry {
+	storedAt := time.Now()
+
+	c.Writer.Header().Set(CacheStatusHeader, "MISS")
+	before := c.Writer.Header().Clone() // headers of outer middlewares (e.g. rate limits) aren't stored.
+
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+
+	c.Next()
+
+	c.Writer = w.ResponseWriter
+
+	if w.Status() != http.StatusOK {
+		return nil
+	}
+
+	ttl, ok := responseTTL(w.Header().Get(cacheControlHeader), rule.TTL)
+	if !ok {
+		return nil
+	}
+
+	header := replayedHeader(w.Header())
+	for k, v := range before {
+		if slices.Equal(header[k], v) {
+			header.Del(k)
+		}
+	}
+
+	return &cache.Entry{
+		Status:    w.Status(),
+		Header:    header,
+		Body:      w.body.Bytes(),
+		Tags:      append(slices.Clone(rule.Tags), tenantTag),
+		StoredAt:  storedAt,
+		ExpiredAt: storedAt.Add(ttl),
+	}
+}
+
+// writeCached - write cached response and abort handlers chain.
+func writeCached(c *gin.Context, e *cache.Entry) {
+	for k, v := range e.Header {
+		c.Writer.Header()[k] = slices.Clone(v)
+	}
+
+	c.Writer.Header().Set(AgeHeader, strconv.Itoa(int(time.Since(e.StoredAt).Seconds())))
+	c.Writer.Header().Set(CacheStatusHeader, "HIT")
+
+	c.Writer.WriteHeader(e.Status)
+	_, _ = c.Writer.Write(e.Body)
+
+	c.Abort()
+}
+
+// responseTTL - ttl of response by its `Cache-Control` (shared cache: `s-maxage` has priority over `max-age`),
+// false if response mustn't be stored.
+func responseTTL(cacheControl string, ttl time.Duration) (time.Duration, bool) {
+	directives := parseCacheControl(cacheControl)
+
+	for _, d := range [...]string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[d]; ok {
+			return 0, false
+		}
+	}
+
+	for _, d := range [...]string{"s-maxage", "max-age"} {
+		if v, ok := directives[d]; ok {
+			if seconds, err := strconv.Atoi(v); err == nil {
+				ttl = time.Duration(seconds) * time.Second
+
+				break
+			}
+		}
+	}
+
+	return ttl, ttl > 0
+}
+
+// isRevalidation - request asks for fresh response.
+func isRevalidation(directives map[string]string) bool {
+	_, noCache := directives["no-cache"]
+
+	return noCache || directives["max-age"] == "0"
+}
+
+// parseCacheControl - directives of `Cache-Control` header with lower case names, e.g. `max-age` => `60`.
+func parseCacheControl(h string) map[string]string {
+	directives := make(map[string]string)
+
+	for _, part := range strings.Split(h, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+
+	return directives
+}
+
+// normalizedQuery - query with sorted keys and values, so order of params doesn't matter.
+func normalizedQuery(q url.Values) string {
+	for _, values := range q {
+		slices.Sort(values)
+	}
+
+	return q.Encode() // keys are sorted by Encode.
+}
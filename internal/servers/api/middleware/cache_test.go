@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/cache"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := cache.NewLRU(cache.LRUConfig{})
+	tenantID := uuid.New()
+
+	var calls atomic.Int32
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) { c.Header("X-Outer", c.Query("page")) })
+	e.GET("/report", CacheMiddleware(store, CacheRule{TTL: time.Minute, Tags: []string{"reports"}}), func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("X-Handler", "1")
+		c.JSON(http.StatusOK, gin.H{"page": c.Query("page")})
+	})
+	e.GET("/private", CacheMiddleware(store, CacheRule{TTL: time.Minute}), func(c *gin.Context) {
+		calls.Add(1)
+		c.Header(cacheControlHeader, "private")
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	do := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	query := "?" + apihelper.TenantIDParam + "=" + tenantID.String()
+
+	w := do("/report" + query + "&page=1&a=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+
+	w = do("/report?a=2&page=1&" + apihelper.TenantIDParam + "=" + tenantID.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "HIT", w.Header().Get(CacheStatusHeader), "order of query params doesn't matter")
+	assert.JSONEq(t, `{"page":"1"}`, w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Handler"))
+	assert.Equal(t, "0", w.Header().Get(AgeHeader))
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.Equal(t, "MISS", do("/report"+query+"&page=1&a=2", cacheControlHeader, "no-cache").Header().Get(CacheStatusHeader))
+	assert.Empty(t, do("/report"+query+"&page=1&a=2", cacheControlHeader, "no-store").Header().Get(CacheStatusHeader))
+	assert.Equal(t, int32(3), calls.Load())
+
+	store.InvalidateTags(cache.TenantTag(tenantID))
+	assert.Equal(t, "MISS", do("/report"+query+"&page=1&a=2").Header().Get(CacheStatusHeader))
+
+	do("/private")
+	assert.Equal(t, "MISS", do("/private").Header().Get(CacheStatusHeader), "private response isn't stored")
+}
+
+func TestCacheMiddleware_Coalescing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const n = 5
+
+	var (
+		calls   atomic.Int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	e := gin.New()
+	e.GET("/report", CacheMiddleware(cache.NewLRU(cache.LRUConfig{}), CacheRule{TTL: time.Minute}), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+
+		c.String(http.StatusOK, "report")
+	})
+
+	var wg sync.WaitGroup
+
+	bodies := make([]string, n)
+	for i := range n {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if i > 0 {
+				<-started
+			}
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/report", nil))
+			bodies[i] = w.Body.String()
+		}()
+	}
+
+	<-started
+	time.Sleep(50 * time.Millisecond) // followers are waiting for leader.
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+
+	for _, body := range bodies {
+		assert.Equal(t, "report", body)
+	}
+}
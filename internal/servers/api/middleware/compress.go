@@ -0,0 +1,362 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+const (
+	encodingGzip     = "gzip"
+	encodingZstd     = "zstd"
+	encodingIdentity = "identity"
+
+	defaultMinCompressSize   = 1024
+	defaultMaxDecompressBody = 10 << 20
+)
+
+// DefaultCompressTypes - media types of responses which are compressed by default, `type/*` matches all subtypes.
+var DefaultCompressTypes = []string{
+	"application/json", "application/problem+json", apihelper.MIMENDJSON, apihelper.MIMEMsgPack,
+	apihelper.MIMEMsgPack2, "application/javascript", "application/xml", "image/svg+xml", "text/*",
+}
+
+// supportedEncodings - content codings of responses in order of preference of server.
+var supportedEncodings = []string{encodingZstd, encodingGzip}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingGzip: {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+
+		return w
+	}},
+	encodingZstd: {New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedDefault))
+
+		return w
+	}},
+}
+
+type (
+	// CompressConfig - settings of CompressMiddleware.
+	CompressConfig struct {
+		MinSize      int      // smaller responses aren't compressed, bytes.
+		ContentTypes []string // compressed media types, @see DefaultCompressTypes.
+	}
+
+	// DecompressConfig - settings of DecompressMiddleware.
+	DecompressConfig struct {
+		MaxBodySize int64 // max size of decompressed request body, bytes.
+	}
+
+	encoder interface {
+		io.WriteCloser
+		Reset(w io.Writer)
+		Flush() error
+	}
+
+	// compressWriter - response writer which holds status and first MinSize bytes of body to decide whether response
+	// is compressed: it must be big enough, has allowed content type and no own `Content-Encoding`.
+	compressWriter struct {
+		gin.ResponseWriter
+		cfg      *CompressConfig
+		encoding string
+		status   int
+		buf      bytes.Buffer
+		decided  bool
+		enc      encoder // not nil if response is compressed.
+	}
+
+	// gzipBody - decompressed request body, closes original body.
+	gzipBody struct {
+		*gzip.Reader
+		body io.ReadCloser
+	}
+)
+
+// CompressMiddleware - compress responses by zstd or gzip, whichever is preferred by `Accept-Encoding` of client
+// (zstd on tie). Responses smaller than MinSize, of not allowed content types, HEAD, 204 and 304 are sent as is.
+// Strong `ETag` of compressed response is made weak: compressed body isn't byte-equal to original.
+func CompressMiddleware(cfg CompressConfig) gin.HandlerFunc {
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = defaultMinCompressSize
+	}
+
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressTypes
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, cfg: &cfg, encoding: encoding}
+		c.Writer = w
+
+		defer func() {
+			c.Writer = w.ResponseWriter
+			w.finish()
+		}()
+
+		c.Next()
+	}
+}
+
+// DecompressMiddleware - decompress gzip request bodies (`Content-Encoding: gzip`), handlers get decompressed body
+// up to MaxBodySize (bigger body fails on read with 413 by validation.BindJSON). Other encodings are rejected by 415.
+func DecompressMiddleware(cfg DecompressConfig) gin.HandlerFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxDecompressBody
+	}
+
+	return func(c *gin.Context) {
+		switch encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); encoding {
+		case "", encodingIdentity:
+		case encodingGzip, "x-gzip":
+			if c.Request.Body == nil || c.Request.Body == http.NoBody {
+				break
+			}
+
+			gz, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				apierror.Abort(c, apperror.BadRequest("request body isn't valid gzip: "+err.Error()))
+
+				return
+			}
+
+			c.Request.Body = http.MaxBytesReader(c.Writer, &gzipBody{Reader: gz, body: c.Request.Body}, cfg.MaxBodySize)
+			c.Request.ContentLength = -1
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Del("Content-Length")
+		default:
+			apierror.Abort(c, apperror.ErrUnsupportedMediaType.WithDetailf("content encoding %q isn't supported",
+				encoding))
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding - supported encoding with the highest q-value of `Accept-Encoding` header, "" - identity.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	qs := make(map[string]float64)
+
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		qs[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+
+	for _, encoding := range supportedEncodings {
+		q, ok := qs[encoding]
+		if !ok {
+			q = qs["*"]
+		}
+
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+
+	return best
+}
+
+// WriteHeader - implements http.ResponseWriter.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	w.status = code
+}
+
+// WriteHeaderNow - implements gin.ResponseWriter, status is written when compression is decided.
+func (w *compressWriter) WriteHeaderNow() {
+	if w.decided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Status - implements gin.ResponseWriter.
+func (w *compressWriter) Status() int {
+	if w.status != 0 && !w.decided {
+		return w.status
+	}
+
+	return w.ResponseWriter.Status()
+}
+
+// Written - implements gin.ResponseWriter.
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Write - implements http.ResponseWriter.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			w.decide(false)
+		} else {
+			w.buf.Write(b)
+			if w.buf.Len() >= w.cfg.MinSize {
+				w.decide(true)
+			}
+
+			return len(b), nil
+		}
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString - implements io.StringWriter.
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush - implements http.Flusher: streamed response is compressed regardless of its size.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// compressible - response has allowed status, content type and no own encoding.
+func (w *compressWriter) compressible() bool {
+	switch status := w.Status(); {
+	case status < http.StatusOK, status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, t := range w.cfg.ContentTypes {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decide - pass held status (with compression headers) and buffered body.
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+
+	if compress {
+		h := w.Header()
+		h.Set("Content-Encoding", w.encoding)
+		h.Add("Vary", "Accept-Encoding")
+		h.Del("Content-Length")
+
+		if etag, ok := apihelper.ParseETag(h.Get(apihelper.ETagHeader)); ok && !etag.Weak {
+			h.Set(apihelper.ETagHeader, apihelper.WeakETag(etag.Tag).String())
+		}
+
+		w.enc = encoderPools[w.encoding].Get().(encoder) //nolint:forcetypeassert // only encoders are in pools.
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if w.enc == nil && w.buf.Len() == 0 { // response without body isn't written, e.g. ErrorMiddleware renders error.
+		return
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+
+	if w.buf.Len() > 0 {
+		if w.enc != nil {
+			_, _ = w.enc.Write(w.buf.Bytes())
+		} else {
+			_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		}
+
+		w.buf.Reset()
+	}
+}
+
+// finish - send small response as is, or complete compressed stream and return encoder to pool.
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if w.buf.Len() > 0 && w.compressible() {
+			w.Header().Add("Vary", "Accept-Encoding") // the other client may get compressed response.
+		}
+
+		w.decide(false)
+	}
+
+	if w.enc == nil {
+		return
+	}
+
+	_ = w.enc.Close()
+	w.enc.Reset(nil)
+	encoderPools[w.encoding].Put(w.enc)
+	w.enc = nil
+}
+
+// Close - implements io.Closer.
+func (b *gzipBody) Close() error {
+	if err := b.Reader.Close(); err != nil {
+		_ = b.body.Close()
+
+		return fmt.Errorf("close gzip body: %w", err)
+	}
+
+	return b.body.Close()
+}
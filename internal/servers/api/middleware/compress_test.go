@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/validation"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	for header, encoding := range map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   "gzip",
+		"gzip, deflate, br":      "gzip",
+		"gzip, zstd":             "zstd",
+		"zstd;q=0.5, gzip":       "gzip",
+		"gzip;q=0, zstd;q=0":     "",
+		"*":                      "zstd",
+		"*;q=0.1, gzip;q=0.5":    "gzip",
+		"GZIP ; q=1.0, br;q=0.9": "gzip",
+	} {
+		assert.Equal(t, encoding, negotiateEncoding(header), header)
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	big := strings.Repeat("a", 2048)
+
+	e := gin.New()
+	e.Use(ErrorMiddleware(), CompressMiddleware(CompressConfig{}))
+	e.GET("/big", func(c *gin.Context) {
+		c.Header(apihelper.ETagHeader, `"1"`)
+		c.String(http.StatusOK, big)
+	})
+	e.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "small") })
+	e.GET("/binary", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(big)) })
+	e.GET("/error", func(c *gin.Context) { _ = c.Error(apperror.NotFound("missing")) })
+
+	do := func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("/big", "gzip")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, `W/"1"`, w.Header().Get(apihelper.ETagHeader))
+
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, big, string(body))
+
+	w = do("/big", "gzip, zstd")
+	assert.Equal(t, "zstd", w.Header().Get("Content-Encoding"))
+
+	zr, err := zstd.NewReader(w.Body)
+	require.NoError(t, err)
+
+	body, err = io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, big, string(body))
+
+	w = do("/big", "")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, big, w.Body.String())
+
+	w = do("/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "small", w.Body.String())
+
+	w = do("/binary", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "not allowed content type")
+	assert.Len(t, w.Body.String(), len(big))
+
+	w = do("/error", "gzip")
+	assert.Equal(t, http.StatusNotFound, w.Code, "error is rendered by error middleware")
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(ErrorMiddleware(), DecompressMiddleware(DecompressConfig{MaxBodySize: 64}))
+	e.POST("/items", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if !validation.BindJSON(c, &req) {
+			return
+		}
+
+		c.String(http.StatusOK, req.Name)
+	})
+
+	gzipped := func(s string) io.Reader {
+		var buf bytes.Buffer
+
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(s))
+		_ = zw.Close()
+
+		return &buf
+	}
+
+	do := func(encoding string, body io.Reader) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/items", body)
+		r.Header.Set("Content-Encoding", encoding)
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("gzip", gzipped(`{"name":"x"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "x", w.Body.String())
+
+	w = do("", strings.NewReader(`{"name":"y"}`))
+	assert.Equal(t, "y", w.Body.String())
+
+	w = do("gzip", gzipped(`{"name":"`+strings.Repeat("z", 100)+`"}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = do("gzip", strings.NewReader("plain"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do("br", strings.NewReader("x"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -2,28 +2,256 @@ package middleware
 
 import (
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORSMiddleware - cors middleware.
-func CORSMiddleware(allowOrigin string) gin.HandlerFunc {
+const anyOrigin = "*"
+
+type (
+	// CORSConfig - CORS settings.
+	CORSConfig struct {
+		// AllowOrigins - allowlist of origins: exact ("https://app.example.com"),
+		// wildcard subdomain ("https://*.example.com") or "*" (any origin, credentials are never allowed then).
+		AllowOrigins     []string
+		AllowMethods     []string
+		AllowHeaders     []string
+		ExposeHeaders    []string
+		AllowCredentials *bool         // nil - not set, so route override keeps default.
+		MaxAge           time.Duration // how long preflight response can be cached.
+
+		// Routes - per-route overrides, key is path prefix (the longest matched prefix wins).
+		// Not empty (set) fields of override replace default ones.
+		Routes map[string]CORSConfig
+	}
+
+	corsPolicy struct {
+		origins          []originPattern
+		anyOrigin        bool
+		allowMethods     string
+		allowHeaders     string
+		exposeHeaders    string
+		allowCredentials bool
+		maxAge           string
+	}
+
+	corsRoute struct {
+		prefix string
+		policy corsPolicy
+	}
+
+	// originPattern - origin matcher, if it has wildcard `prefix` + `*` + `suffix`.
+	originPattern struct {
+		exact    string
+		prefix   string
+		suffix   string
+		wildcard bool
+	}
+)
+
+// CORSMiddleware - cors middleware (https://fetch.spec.whatwg.org/#http-cors-protocol).
+//   - requests without `Origin` are not CORS requests and pass as is.
+//   - disallowed origins get no CORS headers (preflight is rejected with 403).
+//   - `Vary: Origin` is always set, because response depends on the `Origin`.
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	defaultPolicy := newCORSPolicy(cfg)
+
+	routes := make([]corsRoute, 0, len(cfg.Routes))
+	for prefix, override := range cfg.Routes {
+		routes = append(routes, corsRoute{prefix: prefix, policy: newCORSPolicy(cfg.merge(override))})
+	}
+
+	// the longest prefix first.
+	sort.Slice(routes, func(i, j int) bool { return len(routes[i].prefix) > len(routes[j].prefix) })
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type,"+
-			" Content-Length, X-CSRF-Token, Token, session, Origin, Host, Connection, Accept-Encoding,"+
-			" Accept-Language, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		policy := defaultPolicy
+		for _, r := range routes {
+			if strings.HasPrefix(c.Request.URL.Path, r.prefix) {
+				policy = r.policy
+
+				break
+			}
+		}
+
+		policy.handle(c)
+	}
+}
+
+func (p corsPolicy) handle(c *gin.Context) {
+	h := c.Writer.Header()
+	h.Add("Vary", "Origin")
 
-		if c.Request.Method == http.MethodOptions {
-			c.AbortWithStatus(http.StatusNoContent)
+	origin := c.Request.Header.Get("Origin")
+	if origin == "" {
+		c.Next()
+
+		return
+	}
+
+	isPreflight := c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+	if !p.isAllowed(origin) {
+		if isPreflight {
+			c.AbortWithStatus(http.StatusForbidden)
 
 			return
 		}
 
-		c.Request.Header.Del("Origin")
+		c.Next()
+
+		return
+	}
+
+	if p.anyOrigin && !p.allowCredentials {
+		h.Set("Access-Control-Allow-Origin", anyOrigin)
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if p.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !isPreflight {
+		if p.exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+		}
 
 		c.Next()
+
+		return
 	}
+
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	h.Set("Access-Control-Allow-Methods", p.allowMethods)
+
+	if p.allowHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+	}
+
+	if p.maxAge != "" {
+		h.Set("Access-Control-Max-Age", p.maxAge)
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (p corsPolicy) isAllowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	for _, o := range p.origins {
+		if o.match(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newCORSPolicy(cfg CORSConfig) corsPolicy {
+	p := corsPolicy{
+		allowMethods:     strings.Join(cfg.AllowMethods, ", "),
+		allowHeaders:     strings.Join(cfg.AllowHeaders, ", "),
+		exposeHeaders:    strings.Join(cfg.ExposeHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials != nil && *cfg.AllowCredentials,
+	}
+
+	if p.allowMethods == "" {
+		p.allowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	for _, o := range cfg.AllowOrigins {
+		if o == anyOrigin {
+			p.anyOrigin = true
+
+			continue
+		}
+
+		p.origins = append(p.origins, newOriginPattern(o))
+	}
+
+	// browsers reject `*` with credentials, and reflect any origin with credentials is not safe.
+	if p.anyOrigin {
+		p.allowCredentials = false
+	}
+
+	return p
+}
+
+// merge - override not empty (set) fields.
+func (cfg CORSConfig) merge(override CORSConfig) CORSConfig {
+	result := cfg
+	result.Routes = nil
+
+	if len(override.AllowOrigins) > 0 {
+		result.AllowOrigins = override.AllowOrigins
+	}
+
+	if len(override.AllowMethods) > 0 {
+		result.AllowMethods = override.AllowMethods
+	}
+
+	if len(override.AllowHeaders) > 0 {
+		result.AllowHeaders = override.AllowHeaders
+	}
+
+	if len(override.ExposeHeaders) > 0 {
+		result.ExposeHeaders = override.ExposeHeaders
+	}
+
+	if override.MaxAge > 0 {
+		result.MaxAge = override.MaxAge
+	}
+
+	if override.AllowCredentials != nil {
+		result.AllowCredentials = override.AllowCredentials
+	}
+
+	return result
+}
+
+func newOriginPattern(origin string) originPattern {
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+
+	prefix, suffix, found := strings.Cut(origin, "*")
+	if !found {
+		return originPattern{exact: origin}
+	}
+
+	return originPattern{prefix: prefix, suffix: suffix, wildcard: true}
+}
+
+// match - wildcard matches one or more subdomain labels only: `https://*.example.com` doesn't match
+// `https://example.com` and `https://evil.com/.example.com`.
+func (o originPattern) match(origin string) bool {
+	if !o.wildcard {
+		return origin == o.exact
+	}
+
+	if len(origin) <= len(o.prefix)+len(o.suffix) ||
+		!strings.HasPrefix(origin, o.prefix) || !strings.HasSuffix(origin, o.suffix) {
+		return false
+	}
+
+	sub := origin[len(o.prefix) : len(origin)-len(o.suffix)]
+	for _, r := range sub {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '.' {
+			return false
+		}
+	}
+
+	return !strings.HasPrefix(sub, ".") && !strings.HasSuffix(sub, ".")
 }
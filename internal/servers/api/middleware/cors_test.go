@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	allowCredentials, denyCredentials := true, false
+
+	e := gin.New()
+	e.Use(CORSMiddleware(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+		AllowHeaders:     []string{"Content-Type", "X-AUTH-TOKEN"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: &allowCredentials,
+		MaxAge:           10 * time.Minute,
+		Routes: map[string]CORSConfig{
+			"/public":   {AllowOrigins: []string{"*"}},
+			"/embedded": {AllowCredentials: &denyCredentials},
+			"/app":      {MaxAge: time.Minute},
+		},
+	}))
+	e.GET("/items", func(c *gin.Context) { c.String(http.StatusOK, c.GetHeader("Origin")) })
+	e.GET("/public/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.GET("/embedded/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.GET("/app/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method string, path string, origin string, preflight bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do(http.MethodGet, "/items", "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "not CORS request")
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = do(http.MethodGet, "/items", "https://app.example.com", false)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "X-Request-Id", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "https://app.example.com", w.Body.String(), "origin header is not stripped")
+
+	w = do(http.MethodGet, "/items", "https://a.b.example.org", false)
+	assert.Equal(t, "https://a.b.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+
+	for _, origin := range []string{"https://example.org", "https://evil.com", "https://evil.com/.example.org"} {
+		w = do(http.MethodGet, "/items", origin, false)
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	w = do(http.MethodOptions, "/items", "https://app.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Content-Type, X-AUTH-TOKEN", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.NotEmpty(t, w.Header().Get("Access-Control-Allow-Methods"))
+
+	w = do(http.MethodOptions, "/items", "https://evil.com", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = do(http.MethodGet, "/public/items", "https://evil.com", false)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"), "no credentials with any origin")
+
+	w = do(http.MethodGet, "/embedded/items", "https://app.example.com", false)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"), "override disables credentials")
+
+	w = do(http.MethodGet, "/app/items", "https://app.example.com", false)
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), "not set in override - default")
+}
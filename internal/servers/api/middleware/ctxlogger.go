@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+)
+
+// ContextLoggerMiddleware - put request-scoped child of log into request context (@see logger.FromContext)
+// with request id, trace id, method and route. Auth middlewares add user id and tenant id later.
+// Must be used after RequestIDMiddleware and otelgin middleware.
+func ContextLoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		fields := []zapcore.Field{
+			field.RequestID(requestid.FromContext(ctx)),
+			field.Method(c.Request.Method),
+			field.Route(c.FullPath()),
+		}
+
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			fields = append(fields, field.TraceID(sc.TraceID().String()))
+		}
+
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, log.With(fields...)))
+
+		c.Next()
+	}
+}
+
+// addLogFields - add fields to request-scoped logger.
+func addLogFields(c *gin.Context, fields ...zapcore.Field) {
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), fields...))
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+// ErrorMiddleware - centralized error handling of errors attached to request via c.Error (@see apperror):
+//   - if response is not written yet, the last error is written as `application/problem+json`
+//     (@see apierror.Render), not apperror.Error errors are hidden behind 500 Internal server error.
+//   - each error is logged once by request-scoped logger: 5xx - error (with cause), 429/503 - warn, others - info.
+//   - each error is counted in metrics by problem type.
+//
+// Handlers can just `_ = c.Error(err); return` or use apierror.Abort.
+// Must be used after RecoveryWithZap (panics are handled by recovery).
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		log := logger.FromContext(c.Request.Context())
+
+		var last *apperror.Error
+		for _, ge := range c.Errors {
+			last = toAppError(ge)
+
+			metrics.APIErrorsInc(apierror.TypePrefix+last.Code, last.Status)
+			logAppError(log, last)
+		}
+
+		if !c.Writer.Written() {
+			apierror.Render(c, last)
+		}
+	}
+}
+
+// toAppError - binding errors of gin (c.Bind*) are bad requests.
+func toAppError(ge *gin.Error) *apperror.Error {
+	if ge.IsType(gin.ErrorTypeBind) {
+		return apperror.BadRequest(ge.Err.Error())
+	}
+
+	return apperror.From(ge.Err)
+}
+
+func logAppError(log *logger.Logger, e *apperror.Error) {
+	fields := []zapcore.Field{
+		field.String("problem_type", apierror.TypePrefix+e.Code),
+		field.Int("status", e.Status),
+		field.String("detail", e.Detail),
+	}
+
+	if e.Cause != nil {
+		fields = append(fields, field.Error(e.Cause))
+	}
+
+	switch {
+	case e.Status == http.StatusTooManyRequests || e.Status == http.StatusServiceUnavailable:
+		log.Warn(e.Title, fields...)
+	case e.Status >= http.StatusInternalServerError:
+		log.Error(e.Title, fields...)
+	default:
+		log.Info(e.Title, fields...)
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(RequestIDMiddleware(), ErrorMiddleware())
+	e.GET("/not-found", func(c *gin.Context) {
+		_ = c.Error(apperror.NotFound("report 1 not found").With("report_id", 1))
+	})
+	e.GET("/internal", func(c *gin.Context) {
+		_ = c.Error(errors.New("db password is wrong"))
+	})
+
+	do := func(path string) (*httptest.ResponseRecorder, map[string]any) {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		return w, body
+	}
+
+	w, body := do("/not-found")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apierror.ContentType+"; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, apierror.TypePrefix+"not_found", body["type"])
+	assert.Equal(t, "report 1 not found", body["detail"])
+	assert.Equal(t, "GET /not-found", body["instance"])
+	assert.Equal(t, w.Header().Get(XRequestID), body["request_id"])
+	assert.EqualValues(t, 1, body["report_id"], "extension member")
+
+	w, body = do("/internal")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, apierror.TypePrefix+"internal", body["type"])
+	assert.Empty(t, body["detail"], "cause is not shown to client")
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+)
+
+const defaultMaxETagBody = 1 << 20
+
+type (
+	// ETagConfig - settings of ETagMiddleware.
+	ETagConfig struct {
+		MaxBodySize int // bigger responses are streamed without etag.
+	}
+
+	// bufferingWriter - response writer which holds status and body until flush, up to limit bytes.
+	// Over limit (or on http.Flusher.Flush) response is streamed to client.
+	bufferingWriter struct {
+		gin.ResponseWriter
+		status    int
+		body      bytes.Buffer
+		limit     int
+		streaming bool
+	}
+)
+
+// ETagMiddleware - weak `ETag` (hash of body) for successful GET responses without own etag, and 304 for
+// `If-None-Match`/`If-Modified-Since` of client if response isn't modified (own `ETag`/`Last-Modified` of handler
+// are respected). Handlers with version column of resource should use apihelper.NotModified, it skips response
+// rendering at all.
+func ETagMiddleware(cfg ETagConfig) gin.HandlerFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxETagBody
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+
+			return
+		}
+
+		w := &bufferingWriter{ResponseWriter: c.Writer, limit: cfg.MaxBodySize}
+		c.Writer = w
+
+		c.Next()
+
+		c.Writer = w.ResponseWriter
+
+		if w.streaming {
+			return
+		}
+
+		if w.Status() != http.StatusOK {
+			w.flush()
+
+			return
+		}
+
+		etag, _ := apihelper.ParseETag(w.Header().Get(apihelper.ETagHeader))
+		if etag.IsZero() && c.Request.Method == http.MethodGet { // body of HEAD response is empty.
+			etag = apihelper.HashETag(w.body.Bytes())
+			w.Header().Set(apihelper.ETagHeader, etag.String())
+		}
+
+		lastModified, _ := http.ParseTime(w.Header().Get(apihelper.LastModifiedHeader))
+
+		if apihelper.IsNotModified(c.Request, etag, lastModified) {
+			for _, h := range [...]string{"Content-Type", "Content-Length"} {
+				w.Header().Del(h)
+			}
+
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+
+			return
+		}
+
+		w.flush()
+	}
+}
+
+// WriteHeader - implements http.ResponseWriter.
+func (w *bufferingWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	w.status = code
+}
+
+// WriteHeaderNow - implements gin.ResponseWriter, status is written on flush.
+func (w *bufferingWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Status - implements gin.ResponseWriter.
+func (w *bufferingWriter) Status() int {
+	if w.status != 0 && !w.streaming {
+		return w.status
+	}
+
+	return w.ResponseWriter.Status()
+}
+
+// Written - implements gin.ResponseWriter.
+func (w *bufferingWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Write - implements http.ResponseWriter.
+func (w *bufferingWriter) Write(b []byte) (int, error) {
+	if !w.streaming && w.body.Len()+len(b) > w.limit {
+		w.flush()
+	}
+
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+
+	return w.body.Write(b)
+}
+
+// WriteString - implements io.StringWriter.
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush - implements http.Flusher, response is streamed after it.
+func (w *bufferingWriter) Flush() {
+	w.flush()
+	w.ResponseWriter.Flush()
+}
+
+// flush - write held status and body to client, the rest of response is streamed.
+func (w *bufferingWriter) flush() {
+	if w.streaming {
+		return
+	}
+
+	w.streaming = true
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+}
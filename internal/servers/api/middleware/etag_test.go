@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	e := gin.New()
+	e.Use(ETagMiddleware(ETagConfig{MaxBodySize: 64}))
+	e.GET("/items", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": []int{1, 2}}) })
+	e.GET("/big", func(c *gin.Context) { c.String(http.StatusOK, strings.Repeat("x", 100)) })
+	e.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{}) })
+	e.GET("/versioned", func(c *gin.Context) {
+		if apihelper.NotModified(c, apihelper.VersionETag(3), modified) {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"version": 3})
+	})
+
+	do := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("/items")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[1,2]}`, w.Body.String())
+
+	etag := w.Header().Get(apihelper.ETagHeader)
+	assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+
+	w = do("/items", apihelper.IfNoneMatchHeader, etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get(apihelper.ETagHeader))
+
+	w = do("/big")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, w.Body.String(), 100, "big response is streamed")
+	assert.Empty(t, w.Header().Get(apihelper.ETagHeader))
+
+	w = do("/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get(apihelper.ETagHeader))
+
+	w = do("/versioned")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get(apihelper.ETagHeader), "etag of handler is kept")
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", w.Header().Get(apihelper.LastModifiedHeader))
+
+	assert.Equal(t, http.StatusNotModified, do("/versioned", apihelper.IfNoneMatchHeader, `"3"`).Code)
+	assert.Equal(t, http.StatusNotModified,
+		do("/versioned", apihelper.IfModifiedSinceHeader, "Tue, 02 Jan 2024 03:04:05 GMT").Code)
+	assert.Equal(t, http.StatusOK, do("/versioned", apihelper.IfNoneMatchHeader, `"2"`).Code)
+}
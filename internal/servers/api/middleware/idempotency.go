@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/services/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader - request header with client generated key (e.g. uuid) of request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader - response header, `true` if response is replayed.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen      = 255
+	defaultMaxIdempotencyBody = 1 << 20
+)
+
+// notReplayedHeaders - headers of response which are not stored for replay. Body is stored before compression,
+// so encoding headers set by CompressMiddleware are dropped: replay is compressed (or not) anew.
+var notReplayedHeaders = []string{
+	"Date", "Set-Cookie", "Content-Encoding", "Content-Length", "Vary", requestid.Header, IdempotentReplayedHeader,
+}
+
+type (
+	// IdempotencyStore - storage of idempotency keys, @see services/idempotency.Service.
+	IdempotencyStore interface {
+		Begin(ctx context.Context, scope string, key string, fingerprint string) (*idempotency.Response, error)
+		Complete(ctx context.Context, scope string, key string, resp idempotency.Response) error
+		Release(ctx context.Context, scope string, key string) error
+	}
+
+	// IdempotencyConfig - settings of IdempotencyMiddleware.
+	IdempotencyConfig struct {
+		MaxBodySize int64 // max size of request body with idempotency key.
+	}
+
+	// recordingWriter - response writer which keeps copy of response body.
+	recordingWriter struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// IdempotencyMiddleware - honor `Idempotency-Key` header of unsafe requests (POST, PUT, PATCH, DELETE).
+// The first request with key is processed and its response is stored, retries with the same key get stored
+// response (with `Idempotent-Replayed: true` header). Retry with other method, path, query or body and retry
+// while the first request is in flight get 409. Keys are scoped by tenant and client (user or api key), requests
+// without authenticated client are processed as usual. Responses with 5xx aren't stored, so request can be retried.
+func IdempotencyMiddleware(store IdempotencyStore, cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxIdempotencyBody
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isUnsafeMethod(c.Request.Method) {
+			c.Next()
+
+			return
+		}
+
+		scope, ok := idempotencyScope(c)
+		if !ok {
+			c.Next()
+
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLen {
+			apierror.Abort(c, apperror.BadRequest(fmt.Sprintf("%s header is longer than %d",
+				IdempotencyKeyHeader, maxIdempotencyKeyLen)))
+
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c, cfg.MaxBodySize)
+		if err != nil {
+			apierror.Abort(c, err)
+
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		stored, err := store.Begin(ctx, scope, key, fingerprint)
+
+		switch {
+		case errors.Is(err, idempotency.ErrInFlight):
+			apierror.Abort(c, apperror.ErrIdempotencyKeyInUse.WithDetail("request with the same key is in progress"))
+
+			return
+		case errors.Is(err, idempotency.ErrFingerprintMismatch):
+			apierror.Abort(c, apperror.ErrIdempotencyKeyReused.WithDetail("key was used for other request"))
+
+			return
+		case err != nil:
+			apierror.Abort(c, apperror.Internal(fmt.Errorf("idempotency: %w", err)))
+
+			return
+		case stored != nil:
+			replay(c, stored)
+
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		completed := false
+
+		defer func() { // response is stored even if client is gone, key is released if handler panics.
+			finishCtx := context.WithoutCancel(ctx)
+			log := logger.FromContext(ctx)
+
+			if !completed || w.Status() >= http.StatusInternalServerError {
+				if err := store.Release(finishCtx, scope, key); err != nil {
+					log.Error("idempotency release", field.Error(err))
+				}
+
+				return
+			}
+
+			if err := store.Complete(finishCtx, scope, key, idempotency.Response{
+				StatusCode: w.Status(),
+				Header:     replayedHeader(w.Header()),
+				Body:       w.body.Bytes(),
+			}); err != nil {
+				log.Error("idempotency complete", field.Error(err))
+			}
+		}()
+
+		c.Next()
+
+		completed = true
+	}
+}
+
+// Write - implements http.ResponseWriter.
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString - implements io.StringWriter.
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotencyScope - tenant and client of request, false if client isn't authenticated.
+func idempotencyScope(c *gin.Context) (string, bool) {
+	var client string
+
+	if key, err := apihelper.GetAPIToneFromGinCtx[*tables.APIKey](c); err == nil && key != nil {
+		client = "api_key:" + strconv.Itoa(key.ID)
+	} else if userID, ok := apihelper.GetSessionUserID(c); ok {
+		client = "user:" + strconv.Itoa(userID)
+	} else {
+		return "", false
+	}
+
+	tenant := "-"
+	if tenantID := RequestTenant(c); tenantID != uuid.Nil {
+		tenant = tenantID.String()
+	}
+
+	return "tenant:" + tenant + "|" + client, true
+}
+
+// requestFingerprint - sha256 of method, path, query and body. Body is restored for handlers.
+func requestFingerprint(c *gin.Context, maxBodySize int64) (string, error) {
+	var body []byte
+
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1)); err != nil {
+			return "", apperror.BadRequest("can't read request body: " + err.Error())
+		}
+
+		if int64(len(body)) > maxBodySize {
+			return "", apperror.BadRequest(fmt.Sprintf("body of request with %s header is larger than %d bytes",
+				IdempotencyKeyHeader, maxBodySize))
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	for _, part := range []string{c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replay(c *gin.Context, resp *idempotency.Response) {
+	for k, values := range resp.Header {
+		for _, v := range values {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(resp.StatusCode)
+	_, _ = c.Writer.Write(resp.Body)
+	c.Abort()
+}
+
+func replayedHeader(h http.Header) http.Header {
+	stored := h.Clone()
+	for _, k := range notReplayedHeaders {
+		stored.Del(k)
+	}
+
+	return stored
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/idempotency"
+)
+
+type (
+	fakeIdempotencyStore struct {
+		mu   sync.Mutex
+		keys map[string]*storedKey
+	}
+
+	storedKey struct {
+		fingerprint string
+		resp        *idempotency.Response
+	}
+)
+
+func (f *fakeIdempotencyStore) Begin(_ context.Context, scope, key, fp string) (*idempotency.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	k, ok := f.keys[scope+key]
+	switch {
+	case !ok:
+		f.keys[scope+key] = &storedKey{fingerprint: fp}
+
+		return nil, nil
+	case k.fingerprint != fp:
+		return nil, idempotency.ErrFingerprintMismatch
+	case k.resp == nil:
+		return nil, idempotency.ErrInFlight
+	default:
+		return k.resp, nil
+	}
+}
+
+func (f *fakeIdempotencyStore) Complete(_ context.Context, scope, key string, resp idempotency.Response) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.keys[scope+key].resp = &resp
+
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(_ context.Context, scope, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.keys, scope+key)
+
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &fakeIdempotencyStore{keys: make(map[string]*storedKey)}
+	created, failures := 0, 1
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) {
+		if userID, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+			apihelper.SetSessionForRequest(c, &tables.Session{ID: userID, UserID: userID})
+		}
+	}, IdempotencyMiddleware(store, IdempotencyConfig{MaxBodySize: 16}))
+	e.POST("/items", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		created++
+		c.Header("Location", "/items/1")
+		c.JSON(http.StatusCreated, gin.H{"n": created, "body": string(body)})
+	})
+	e.POST("/flaky", func(c *gin.Context) {
+		if failures > 0 {
+			failures--
+			c.Status(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		c.Status(http.StatusOK)
+	})
+
+	do := func(path, user, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("X-User", user)
+		r.Header.Set(IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("/items", "1", "k1", "a")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"n":1,"body":"a"}`, w.Body.String(), "handler reads restored body")
+
+	w = do("/items", "1", "k1", "a")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"n":1,"body":"a"}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "/items/1", w.Header().Get("Location"))
+
+	w = do("/items", "1", "k1", "b")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "idempotency_key_reused")
+
+	assert.Equal(t, http.StatusCreated, do("/items", "2", "k1", "a").Code, "keys are scoped by client")
+	assert.Equal(t, http.StatusCreated, do("/items", "", "k1", "a").Code, "anonymous requests aren't deduplicated")
+	assert.Equal(t, http.StatusCreated, do("/items", "1", "", "a").Code, "no key")
+	assert.Equal(t, 4, created)
+
+	assert.Equal(t, http.StatusBadRequest, do("/items", "1", "k2", strings.Repeat("x", 17)).Code)
+	assert.Equal(t, http.StatusBadRequest, do("/items", "1", strings.Repeat("k", 256), "a").Code)
+
+	assert.Equal(t, http.StatusServiceUnavailable, do("/flaky", "1", "k3", "").Code)
+	assert.Equal(t, http.StatusOK, do("/flaky", "1", "k3", "").Code, "failed request is retried")
+
+	do("/items", "1", "k4", "a")
+	store.keys["tenant:-|user:1"+"k4"].resp = nil // the first request is in flight.
+
+	w = do("/items", "1", "k4", "a")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "idempotency_key_in_use")
+}
+
+func TestIdempotencyMiddleware_Compress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := &fakeIdempotencyStore{keys: make(map[string]*storedKey)}
+	big := strings.Repeat("a", 2048)
+
+	e := gin.New()
+	e.Use(CompressMiddleware(CompressConfig{}), func(c *gin.Context) {
+		apihelper.SetSessionForRequest(c, &tables.Session{ID: 1, UserID: 1})
+	}, IdempotencyMiddleware(store, IdempotencyConfig{}))
+	e.POST("/items", func(c *gin.Context) { c.String(http.StatusCreated, big) })
+
+	do := func(acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/items", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do("gzip")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	w = do("")
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, w.Header().Get("Content-Encoding"), "replay isn't labeled as compressed")
+	assert.Empty(t, w.Header().Get("Vary"))
+	assert.Equal(t, big, w.Body.String())
+
+	w = do("gzip")
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "replay is compressed anew")
+
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, big, string(body))
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/services/concurrency"
+)
+
+// Priority - priority class of request for load shedding.
+type Priority string
+
+const (
+	PriorityCritical Priority = "critical" // never shed, not limited at all (health checks, admin routes).
+	PriorityNormal   Priority = "normal"   // waits in queue for free slot, shed after queue timeout.
+	PriorityLow      Priority = "low"      // shed immediately if limit is reached.
+)
+
+// LoadShedConfig - load shedding settings.
+type LoadShedConfig struct {
+	CriticalPaths []string // route paths with sub-paths, e.g. "/api/v1/roles" (also "/api/v1/roles/:id").
+	LowPaths      []string // route paths with sub-paths.
+	RetryAfter    time.Duration
+}
+
+// LoadShedMiddleware - cap in-flight requests with adaptive limiter, shed overload with 503.
+// Requests which failed with 503/504 or took longer than limiter threshold decrease the limit.
+func LoadShedMiddleware(l *concurrency.Limiter, cfg LoadShedConfig) gin.HandlerFunc {
+	retryAfter := strconv.Itoa(max(1, int(cfg.RetryAfter.Seconds())))
+
+	return func(c *gin.Context) {
+		p := cfg.priority(c.FullPath())
+		if p == PriorityCritical {
+			c.Next()
+
+			return
+		}
+
+		token, err := l.Acquire(c.Request.Context(), p == PriorityNormal)
+		reportConcurrency(l)
+
+		if err != nil {
+			metrics.ShedRequestsInc(string(p))
+
+			c.Header("Retry-After", retryAfter)
+			apierror.Abort(c, apperror.ErrOverloaded.WithDetail("too many requests in flight, retry after "+retryAfter+"s"))
+
+			return
+		}
+
+		defer func() {
+			status := c.Writer.Status()
+			token.Release(status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout)
+			reportConcurrency(l)
+		}()
+
+		c.Next()
+	}
+}
+
+func (cfg LoadShedConfig) priority(path string) Priority {
+	switch {
+	case hasAnyPathPrefix(path, cfg.CriticalPaths):
+		return PriorityCritical
+	case hasAnyPathPrefix(path, cfg.LowPaths):
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// hasAnyPathPrefix - path is one of prefixes or its sub-path, prefixes are matched by whole segments:
+// "/api/v1/users" matches "/api/v1/users/:id", but not "/api/v1/usersX".
+func hasAnyPathPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		rest, ok := strings.CutPrefix(path, p)
+		if ok && (rest == "" || rest[0] == '/' || strings.HasSuffix(p, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reportConcurrency(l *concurrency.Limiter) {
+	s := l.Stats()
+	metrics.SetConcurrency(s.Limit, s.InFlight, s.Queued)
+}
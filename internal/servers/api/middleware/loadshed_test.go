@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/services/concurrency"
+)
+
+func TestLoadShedMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	l := concurrency.New(concurrency.Config{InitialLimit: 1, MinLimit: 1, MaxLimit: 1})
+
+	e := gin.New()
+	e.Use(LoadShedMiddleware(l, LoadShedConfig{CriticalPaths: []string{"/health"}, RetryAfter: 2 * time.Second}))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	e.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	e.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "critical routes are never shed")
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLoadShedConfig_Priority(t *testing.T) {
+	cfg := LoadShedConfig{
+		CriticalPaths: []string{"/health", "/api/v1/users", "/api/v1/roles/"},
+		LowPaths:      []string{"/api/v1/search"},
+	}
+
+	for path, p := range map[string]Priority{
+		"/health":            PriorityCritical,
+		"/api/v1/users":      PriorityCritical,
+		"/api/v1/users/:id":  PriorityCritical,
+		"/api/v1/usersX":     PriorityNormal,
+		"/api/v1/roles/:id":  PriorityCritical,
+		"/api/v1/search":     PriorityLow,
+		"/api/v1/searchable": PriorityNormal,
+		"/api/v1/api-keys":   PriorityNormal,
+	} {
+		assert.Equal(t, p, cfg.priority(path), path)
+	}
+}
@@ -6,12 +6,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
 )
 
 // Ginzap returns a gin.HandlerFunc (middleware) that logs requests using uber-go/zap.
 //
-// Requests with errors are logged using zap.Error().
-// Requests without errors are logged using zap.Info().
+// All requests are logged using zap.Info() (access log).
+// Errors of requests are logged only once by ErrorMiddleware.
 //
 // It receives:
 //  1. A time package format string (e.g. time.RFC3339).
@@ -26,27 +29,22 @@ func Ginzap(logger *zap.Logger, timeFormat string, utc bool) gin.HandlerFunc {
 
 		end := time.Now().UTC()
 		latency := end.Sub(start)
+		requestID := field.RequestID(requestid.FromContext(c.Request.Context()))
 
 		if utc {
 			end = end.UTC()
 		}
 
-		if len(c.Errors) > 0 {
-			// Append error field if this is an erroneous request.
-			for _, e := range c.Errors.Errors() {
-				logger.Error(e)
-			}
-		} else {
-			logger.Info(path,
-				zap.Int("status", c.Writer.Status()),
-				zap.String("method", c.Request.Method),
-				zap.String("path", path),
-				zap.String("query", query),
-				zap.String("ip", c.ClientIP()),
-				zap.String("user-agent", c.Request.UserAgent()),
-				zap.String("time", end.Format(timeFormat)),
-				zap.Duration("latency", latency),
-			)
-		}
+		logger.Info(path,
+			zap.Int("status", c.Writer.Status()),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.String("query", query),
+			zap.String("ip", c.ClientIP()),
+			zap.String("user-agent", c.Request.UserAgent()),
+			zap.String("time", end.Format(timeFormat)),
+			zap.Duration("latency", latency),
+			requestID,
+		)
 	}
 }
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/services/metering"
+)
+
+// QuotaWarningHeader - response header with quotas of tenant whose soft limit is exceeded,
+// e.g. `requests/day 950/900` (used/soft limit), several quotas are comma separated.
+const QuotaWarningHeader = "Quota-Warning"
+
+// Meter - usage metering, @see services/metering.Service.
+type Meter interface {
+	Consume(ctx context.Context, tenantID uuid.UUID, metric metering.Metric, n int64) ([]metering.Usage, error)
+}
+
+// QuotaMiddleware - count request of tenant as usage of metric and reject it with `quota_exceeded` problem
+// (429 and `Retry-After`) if hard limit is exceeded. Only requests of api keys are metered: tenant of other requests
+// (users, public routes) is set by client, so anyone could use up quota of any tenant.
+// If metering fails, request is allowed (fail-open) and error is logged.
+func QuotaMiddleware(meter Meter, metric metering.Metric) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID, ok := apihelper.GetTenantUUIDFromRequest(c)
+		if !ok {
+			c.Next()
+
+			return
+		}
+
+		usages, err := meter.Consume(c.Request.Context(), tenantID, metric, 1)
+
+		var qe *metering.QuotaError
+		if errors.As(err, &qe) {
+			c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(time.Until(qe.ResetAt)), 1)))
+			apierror.Abort(c, err)
+
+			return
+		}
+
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("quota", field.String("tenant_id", tenantID.String()),
+				field.Error(err))
+			c.Next()
+
+			return
+		}
+
+		var warnings []string
+
+		for _, u := range usages {
+			if u.SoftExceeded() {
+				warnings = append(warnings, fmt.Sprintf("%s/%s %d/%d", u.Metric, u.Period, u.Used, u.Soft))
+			}
+		}
+
+		if len(warnings) > 0 {
+			c.Header(QuotaWarningHeader, strings.Join(warnings, ", "))
+		}
+
+		c.Next()
+	}
+}
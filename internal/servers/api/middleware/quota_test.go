@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/metering"
+)
+
+type fakeMeter struct {
+	used     int64
+	consumed map[uuid.UUID]int64
+}
+
+func (f *fakeMeter) Consume(_ context.Context, id uuid.UUID, m metering.Metric, n int64) ([]metering.Usage, error) {
+	q := metering.Quota{Metric: m, Period: metering.Day, Soft: 1, Hard: 2}
+	u := metering.Usage{Quota: q, Used: f.used, ResetAt: time.Now().Add(time.Minute)}
+
+	if f.used+n > q.Hard {
+		return nil, &metering.QuotaError{Usage: u}
+	}
+
+	f.used += n
+	f.consumed[id] += n
+	u.Used = f.used
+
+	return []metering.Usage{u}, nil
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	meter := &fakeMeter{consumed: make(map[uuid.UUID]int64)}
+	tenantID := uuid.New()
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) {
+		if keyOf, err := uuid.Parse(c.GetHeader("X-Key-Tenant")); err == nil {
+			apihelper.SetTenantUUIDForRequest(c, keyOf)
+		}
+	}, QuotaMiddleware(meter, metering.Requests))
+	e.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(keyOf uuid.UUID, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		if keyOf != uuid.Nil {
+			r.Header.Set("X-Key-Tenant", keyOf.String())
+		}
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do(uuid.Nil, "").Code, "no tenant")
+	assert.Equal(t, http.StatusOK, do(uuid.Nil, "?tenant_id="+tenantID.String()).Code, "tenant set by client")
+	assert.Empty(t, meter.consumed, "only api keys are metered")
+
+	w := do(tenantID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(QuotaWarningHeader))
+
+	w = do(tenantID, "?tenant_id="+uuid.NewString())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "requests/day 2/1", w.Header().Get(QuotaWarningHeader))
+
+	w = do(tenantID, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"type":"/api/v1/problems/quota_exceeded"`)
+	assert.Contains(t, w.Body.String(), `"limit":2`)
+
+	assert.Equal(t, map[uuid.UUID]int64{tenantID: 2}, meter.consumed)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/services/ratelimit"
+)
+
+// RateLimitKey - kind of client identity used as rate limit bucket key.
+type RateLimitKey string
+
+const (
+	RateLimitByAPIKey RateLimitKey = "api_key"
+	RateLimitByTenant RateLimitKey = "tenant"
+	RateLimitByUser   RateLimitKey = "user"
+	RateLimitByIP     RateLimitKey = "ip" // client ip respects engine's RemoteIPHeaders, @see api.NewEngine.
+)
+
+// DefaultRateLimitKeys - the most specific identity first, ip is always available.
+var DefaultRateLimitKeys = []RateLimitKey{RateLimitByAPIKey, RateLimitByTenant, RateLimitByUser, RateLimitByIP}
+
+// RateLimitConfig - rate limiter settings.
+type RateLimitConfig struct {
+	Prefix  string                     // prefix of bucket keys, limiters with the same backend must have different ones.
+	KeyBy   []RateLimitKey             // first available identity of client is used.
+	Default ratelimit.Limit            // limit for all routes of client.
+	Routes  map[string]ratelimit.Limit // per-route limits, key is "METHOD /full/path", e.g. "POST /api/v1/sessions".
+}
+
+// RateLimitMiddleware - token bucket rate limiter. Sets `RateLimit-*` headers, and responds 429 if limit exceeded.
+// If limiter backend fails, request is allowed (fail-open) and error is logged.
+func RateLimitMiddleware(l ratelimit.Limiter, cfg RateLimitConfig) gin.HandlerFunc {
+	if len(cfg.KeyBy) == 0 {
+		cfg.KeyBy = DefaultRateLimitKeys
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+
+		limit, bucket := cfg.Default, "default"
+		if routeLimit, ok := cfg.Routes[route]; ok {
+			limit, bucket = routeLimit, route
+		}
+
+		if limit.IsZero() {
+			c.Next()
+
+			return
+		}
+
+		key := cfg.Prefix + bucket + "|" + rateLimitIdentity(c, cfg.KeyBy)
+
+		res, err := l.Take(c.Request.Context(), key, limit)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("rate limiter", field.String("key", key), field.Error(err))
+			c.Next()
+
+			return
+		}
+
+		setRateLimitHeaders(c, res)
+
+		if !res.Allowed {
+			retryAfter := strconv.Itoa(ceilSeconds(res.RetryAfter))
+
+			c.Header("Retry-After", retryAfter)
+			apierror.Abort(c, apperror.ErrRateLimited.WithDetail("rate limit exceeded, retry after "+retryAfter+"s"))
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitIdentity(c *gin.Context, keyBy []RateLimitKey) string {
+	for _, k := range keyBy {
+		switch k {
+		case RateLimitByAPIKey:
+			if key, err := apihelper.GetAPIToneFromGinCtx[*tables.APIKey](c); err == nil && key != nil {
+				return string(k) + ":" + strconv.Itoa(key.ID)
+			}
+		case RateLimitByTenant:
+			if tID, ok := apihelper.GetTenantUUIDFromRequest(c); ok {
+				return string(k) + ":" + tID.String()
+			}
+		case RateLimitByUser:
+			if userID, ok := apihelper.GetSessionUserID(c); ok {
+				return string(k) + ":" + strconv.Itoa(userID)
+			}
+		case RateLimitByIP:
+			return string(k) + ":" + c.ClientIP()
+		}
+	}
+
+	return string(RateLimitByIP) + ":" + c.ClientIP()
+}
+
+func setRateLimitHeaders(c *gin.Context, res ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/services/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(RateLimitMiddleware(ratelimit.NewMemory(), RateLimitConfig{
+		KeyBy:   []RateLimitKey{RateLimitByIP},
+		Default: ratelimit.Limit{Requests: 1, Per: time.Minute, Burst: 2},
+		Routes: map[string]ratelimit.Limit{
+			"POST /login": {Requests: 1, Per: time.Minute, Burst: 1},
+		},
+	}))
+	e.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.POST("/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method string, path string, ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do(http.MethodGet, "/items", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/items", "10.0.0.1").Code)
+
+	w = do(http.MethodGet, "/items", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"status":429`)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/items", "10.0.0.2").Code, "other client")
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/login", "10.0.0.1").Code, "own route bucket")
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "/login", "10.0.0.1").Code)
+}
+
+func TestRateLimitMiddleware_Prefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewMemory()
+
+	e := gin.New()
+	e.Use(RateLimitMiddleware(limiter, RateLimitConfig{
+		Prefix:  "pre_auth:",
+		KeyBy:   []RateLimitKey{RateLimitByIP},
+		Default: ratelimit.Limit{Requests: 1, Per: time.Minute, Burst: 1},
+	}), RateLimitMiddleware(limiter, RateLimitConfig{
+		KeyBy:   []RateLimitKey{RateLimitByIP},
+		Default: ratelimit.Limit{Requests: 1, Per: time.Minute, Burst: 1},
+	}))
+	e.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "buckets of limiters with different prefixes are separate")
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
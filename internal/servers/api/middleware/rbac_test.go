@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+type fakeChecker rbac.Rights
+
+func (f fakeChecker) HasPermission(_ context.Context, _ int, p rbac.Permission) (bool, error) {
+	return rbac.Rights(f).Has(p), nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newEngine := func(withUser bool) *gin.Engine {
+		e := gin.New()
+		e.Use(func(c *gin.Context) {
+			if withUser {
+				apihelper.SetSessionForRequest(c, &tables.Session{ID: 1, UserID: 42})
+			}
+		})
+		e.GET("/admin", RequirePermission(fakeChecker(rbac.ReadReports), rbac.ReadReports),
+			func(c *gin.Context) { c.Status(http.StatusOK) })
+		e.GET("/root", RequirePermission(fakeChecker(rbac.ReadReports), rbac.ManageRoles),
+			func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		return e
+	}
+
+	for _, tc := range []struct {
+		path     string
+		withUser bool
+		status   int
+	}{
+		{"/admin", true, http.StatusOK},
+		{"/root", true, http.StatusForbidden},
+		{"/admin", false, http.StatusUnauthorized},
+	} {
+		w := httptest.NewRecorder()
+		newEngine(tc.withUser).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		assert.Equal(t, tc.status, w.Code, tc.path)
+	}
+}
+
+func TestRequirePermission_UserOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) {
+		apihelper.SetAPITokenToGinCtx(c, &tables.APIKey{ID: 1, Rights: int16(rbac.ReadReports | rbac.ManageTenants)})
+	})
+	e.GET("/reports", RequirePermission(fakeChecker(0), rbac.ReadReports),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+	e.GET("/tenants", RequirePermission(fakeChecker(0), rbac.ManageTenants),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for path, status := range map[string]int{"/reports": http.StatusOK, "/tenants": http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -1,8 +1,8 @@
 package middleware
 
 import (
+	"fmt"
 	"net"
-	"net/http"
 	"net/http/httputil"
 	"os"
 	"runtime/debug"
@@ -11,6 +11,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
 )
 
 // RecoveryWithZap returns a gin.HandlerFunc (middleware)
@@ -37,11 +42,13 @@ func RecoveryWithZap(logger *zap.Logger, stack bool) gin.HandlerFunc {
 				}
 
 				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				requestID := field.RequestID(requestid.FromContext(c.Request.Context()))
 
 				if brokenPipe {
 					logger.Error(c.Request.URL.Path,
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
+						requestID,
 					)
 					// If the connection is dead, we can't write a status to it.
 					_ = c.Error(err.(error))
@@ -56,6 +63,7 @@ func RecoveryWithZap(logger *zap.Logger, stack bool) gin.HandlerFunc {
 						zap.Time("time", time.Now().UTC()),
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
+						requestID,
 						zap.String("stack", string(debug.Stack())),
 					)
 				} else {
@@ -63,10 +71,11 @@ func RecoveryWithZap(logger *zap.Logger, stack bool) gin.HandlerFunc {
 						zap.Time("time", time.Now().UTC()),
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
+						requestID,
 					)
 				}
 
-				c.AbortWithStatus(http.StatusInternalServerError)
+				apierror.Abort(c, apperror.Internal(fmt.Errorf("panic: %v", err)))
 			}
 		}()
 
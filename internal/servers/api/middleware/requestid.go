@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+)
+
+// XRequestID - header and gin context key of request id.
+const XRequestID = requestid.Header
+
+// RequestIDMiddleware - correlation id of request:
+//   - valid upstream `X-Request-Id` is reused, otherwise trace-id of W3C `traceparent`,
+//     otherwise new uuid is generated.
+//   - id is stored in request context.Context (@see requestid.FromContext), so it's available for logs,
+//     db queries and outbound http calls (@see requestid.Transport).
+//   - id is returned in `X-Request-Id` response header.
+//
+// Must be the first middleware, so all others (logger, recovery) can use request id.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestid.FromRequest(c.Request)
+
+		c.Request = c.Request.WithContext(requestid.WithContext(c.Request.Context(), id))
+		c.Set(XRequestID, id)
+		c.Writer.Header().Set(XRequestID, id)
+
+		c.Next()
+	}
+}
+
+// GetRequestID - request id of current request.
+func GetRequestID(c *gin.Context) string {
+	return requestid.FromContext(c.Request.Context())
+}
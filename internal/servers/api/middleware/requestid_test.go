@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(RequestIDMiddleware())
+	e.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, GetRequestID(c)) })
+	e.GET("/fail", func(c *gin.Context) {
+		apierror.Abort(c, apperror.BadRequest(""))
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	r.Header.Set(XRequestID, "upstream-id")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	assert.Equal(t, "upstream-id", w.Header().Get(XRequestID))
+	assert.Equal(t, "upstream-id", w.Body.String())
+
+	r = httptest.NewRequest(http.MethodGet, "/fail", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+
+	var body apierror.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body.RequestID)
+	assert.Equal(t, body.RequestID, w.Header().Get(XRequestID))
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+const defaultSessionCookieName = "session"
+
+type (
+	// SessionValidator - validate session token, @see services/session.Service.
+	SessionValidator interface {
+		Validate(ctx context.Context, token string) (session *tables.Session, renewed bool, err error)
+	}
+
+	// SessionCookie - settings of session cookie.
+	SessionCookie struct {
+		Name   string
+		Domain string
+		Secure bool
+	}
+)
+
+// SessionAuthMiddleware - authenticate requests by session cookie (split token).
+func SessionAuthMiddleware(v SessionValidator, cookie SessionCookie) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticateSession(c, v, cookie) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateSession - validate session cookie, abort request with 401 if it's not valid.
+func authenticateSession(c *gin.Context, v SessionValidator, cookie SessionCookie) bool {
+	token, err := c.Cookie(cookie.name())
+	if err != nil || token == "" {
+		abortUnauthorized(c, "session cookie is missing")
+
+		return false
+	}
+
+	session, renewed, err := v.Validate(c.Request.Context(), token)
+	if err != nil {
+		cookie.Clear(c)
+		abortUnauthorized(c, "session is invalid or expired")
+
+		return false
+	}
+
+	if renewed {
+		cookie.Set(c, token, session.ExpiredAt)
+	}
+
+	apihelper.SetSessionForRequest(c, session)
+	addLogFields(c, field.UserID(session.UserID))
+
+	return true
+}
+
+// Set - set session cookie (HttpOnly, SameSite=Lax).
+func (sc SessionCookie) Set(c *gin.Context, token string, expiredAt time.Time) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sc.name(), token, int(time.Until(expiredAt).Seconds()), "/", sc.Domain, sc.Secure, true)
+}
+
+// Clear - remove session cookie on client side.
+func (sc SessionCookie) Clear(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sc.name(), "", -1, "/", sc.Domain, sc.Secure, true)
+}
+
+func (sc SessionCookie) name() string {
+	if sc.Name == "" {
+		return defaultSessionCookieName
+	}
+
+	return sc.Name
+}
+
+func abortUnauthorized(c *gin.Context, detail string) {
+	apierror.Abort(c, apperror.Unauthorized(detail))
+}
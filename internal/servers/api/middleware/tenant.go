@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+const (
+	// requestTenantKey - gin context key of tenant resolved by RequestTenant.
+	requestTenantKey = "middleware:request_tenant"
+
+	// maxTenantBodySize - tenant of bigger JSON body isn't resolved.
+	maxTenantBodySize = 1 << 20
+)
+
+type (
+	// TenantProvider - cached tenants, @see services/tenant.Service.
+	TenantProvider interface {
+		Tenant(ctx context.Context, id uuid.UUID) (*tables.Tenant, error)
+	}
+
+	// readCloser - body which is partially read and restored.
+	readCloser struct {
+		io.Reader
+		io.Closer
+	}
+)
+
+// TenantStatusMiddleware - reject requests of suspended and deleted tenants with `tenant_suspended` problem.
+// Tenant of request is RequestTenant. Not registered tenants are allowed.
+func TenantStatusMiddleware(tenants TenantProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := RequestTenant(c)
+		if tenantID == uuid.Nil {
+			c.Next()
+
+			return
+		}
+
+		if !CheckTenantActive(c, tenants, tenantID) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CheckTenantActive - abort request of suspended or deleted tenant with `tenant_suspended` problem,
+// handlers check it before writes of tenant data. Not registered tenants are allowed.
+func CheckTenantActive(c *gin.Context, tenants TenantProvider, tenantID uuid.UUID) bool {
+	t, err := tenants.Tenant(c.Request.Context(), tenantID)
+	if err != nil {
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("tenant status: %w", err)))
+
+		return false
+	}
+
+	if t != nil && !t.IsActive() {
+		apierror.Abort(c, apperror.ErrTenantSuspended.WithDetailf("tenant %s is %s", tenantID, t.Status).
+			With("tenant_id", tenantID.String()))
+
+		return false
+	}
+
+	return true
+}
+
+// AuthorizeTenant - check that caller may act on tenant, else abort request: api key acts only on own tenant,
+// user (users aren't members of tenants) acts on any tenant only with `tenants:manage` permission.
+// Must be called after auth middleware.
+func AuthorizeTenant(c *gin.Context, checker PermissionChecker, tenantID uuid.UUID) bool {
+	if keyTenantID, ok := apihelper.GetTenantUUIDFromRequest(c); ok {
+		if keyTenantID != tenantID {
+			apierror.Abort(c, apperror.Forbidden("tenant of api key doesn't match tenant_id"))
+
+			return false
+		}
+
+		return true
+	}
+
+	userID, ok := apihelper.GetSessionUserID(c)
+	if !ok {
+		abortUnauthorized(c, "authentication is required")
+
+		return false
+	}
+
+	allowed, err := checker.HasPermission(c.Request.Context(), userID, rbac.ManageTenants)
+	if err != nil {
+		apierror.Abort(c, apperror.Internal(fmt.Errorf("rbac check permission: %w", err)))
+
+		return false
+	}
+
+	if !allowed {
+		abortForbidden(c, rbac.ManageTenants)
+
+		return false
+	}
+
+	LogTenant(c, tenantID)
+
+	return true
+}
+
+// LogTenant - add tenant to request-scoped logger, when tenant of user request is authorized
+// (tenant of api key is added by auth middleware).
+func LogTenant(c *gin.Context, tenantID uuid.UUID) {
+	addLogFields(c, field.TenantID(tenantID.String()))
+}
+
+// RequireTenant - require tenant of request (RequestTenant) and authorize caller on it, @see AuthorizeTenant.
+// Must be used after auth middleware and before middlewares which trust tenant of request, e.g. CacheMiddleware.
+func RequireTenant(checker PermissionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := RequestTenant(c)
+		if tenantID == uuid.Nil {
+			apierror.Abort(c, apperror.BadQueryParam(apihelper.TenantIDParam, "tenant is required"))
+
+			return
+		}
+
+		if !AuthorizeTenant(c, checker, tenantID) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequestTenant - tenant of request: tenant of api key, else (users) `tenant_id` query param or `tenant_id` field
+// of JSON body, uuid.Nil if request has no tenant. It's resolved once per request, so middlewares (status,
+// idempotency, cache) and handlers act on the same tenant. NB! Tenant of user isn't authorized, @see AuthorizeTenant.
+func RequestTenant(c *gin.Context) uuid.UUID {
+	if v, ok := c.Get(requestTenantKey); ok {
+		if tenantID, ok := v.(uuid.UUID); ok {
+			return tenantID
+		}
+	}
+
+	tenantID := resolveRequestTenant(c)
+	c.Set(requestTenantKey, tenantID)
+
+	return tenantID
+}
+
+func resolveRequestTenant(c *gin.Context) uuid.UUID {
+	if tenantID, ok := apihelper.GetTenantUUIDFromRequest(c); ok {
+		return tenantID
+	}
+
+	if v := c.Query(apihelper.TenantIDParam); v != "" {
+		tenantID, _ := uuid.Parse(v)
+
+		return tenantID
+	}
+
+	return bodyTenant(c)
+}
+
+// bodyTenant - `tenant_id` field of JSON body (decoded like binding of handler does), body is restored for handlers.
+func bodyTenant(c *gin.Context) uuid.UUID {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody || c.ContentType() != binding.MIMEJSON {
+		return uuid.Nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTenantBodySize+1))
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+
+	if err != nil || len(body) > maxTenantBodySize {
+		return uuid.Nil
+	}
+
+	var v struct {
+		TenantID uuid.UUID `json:"tenant_id"`
+	}
+
+	if json.Unmarshal(body, &v) != nil {
+		return uuid.Nil
+	}
+
+	return v.TenantID
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/apihelper"
+	"github.com/imperiuse/go-app-skeleton/internal/services/cache"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+type fakeTenants map[uuid.UUID]*tables.Tenant
+
+func (f fakeTenants) Tenant(_ context.Context, id uuid.UUID) (*tables.Tenant, error) {
+	return f[id], nil
+}
+
+func TestTenantStatusMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	active, suspended, deleted, unknown := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	tenants := fakeTenants{
+		active:    {ID: active, Status: tables.TenantActive},
+		suspended: {ID: suspended, Status: tables.TenantSuspended},
+		deleted:   {ID: deleted, Status: tables.TenantDeleted},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		keyOf    uuid.UUID // tenant of api key.
+		query    string
+		body     string // JSON.
+		status   int
+		contains string
+	}{
+		{name: "no tenant", status: http.StatusOK},
+		{name: "active key", keyOf: active, status: http.StatusOK},
+		{name: "suspended key", keyOf: suspended, status: http.StatusForbidden, contains: "tenant_suspended"},
+		{name: "key wins", keyOf: active, query: suspended.String(), status: http.StatusOK},
+		{name: "deleted param", query: deleted.String(), status: http.StatusForbidden, contains: "is deleted"},
+		{name: "unknown param", query: unknown.String(), status: http.StatusOK},
+		{name: "invalid param", query: "x", status: http.StatusOK},
+		{name: "suspended body", body: `{"tenant_id":"` + suspended.String() + `"}`, status: http.StatusForbidden},
+	} {
+		e := gin.New()
+		e.Use(func(c *gin.Context) {
+			if tc.keyOf != uuid.Nil {
+				apihelper.SetTenantUUIDForRequest(c, tc.keyOf)
+			}
+		}, TenantStatusMiddleware(tenants))
+		e.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		r := httptest.NewRequest(http.MethodPost, "/?tenant_id="+tc.query, strings.NewReader(tc.body))
+		r.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.Equal(t, tc.status, w.Code, tc.name)
+		assert.Contains(t, w.Body.String(), tc.contains, tc.name)
+	}
+}
+
+func TestAuthorizeTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	own, other := uuid.New(), uuid.New()
+
+	for _, tc := range []struct {
+		name   string
+		keyOf  uuid.UUID // tenant of api key.
+		user   bool
+		rights rbac.Rights
+		status int
+	}{
+		{name: "own tenant of key", keyOf: own, status: http.StatusOK},
+		{name: "other tenant of key", keyOf: other, status: http.StatusForbidden},
+		{name: "user without permission", user: true, rights: rbac.ManageAPIKeys, status: http.StatusForbidden},
+		{name: "user with permission", user: true, rights: rbac.ManageTenants, status: http.StatusOK},
+		{name: "anonymous", status: http.StatusUnauthorized},
+	} {
+		e := gin.New()
+		e.Use(ErrorMiddleware(), func(c *gin.Context) {
+			if tc.keyOf != uuid.Nil {
+				apihelper.SetTenantUUIDForRequest(c, tc.keyOf)
+			}
+
+			if tc.user {
+				apihelper.SetSessionForRequest(c, &tables.Session{ID: 1, UserID: 42})
+			}
+		})
+		e.GET("/", func(c *gin.Context) {
+			if AuthorizeTenant(c, fakeChecker(tc.rights), own) {
+				c.Status(http.StatusOK)
+			}
+		})
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, tc.status, w.Code, tc.name)
+	}
+}
+
+func TestAuthorizeTenant_Log(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	tenantID := uuid.New()
+
+	e := gin.New()
+	e.GET("/", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), zap.New(core)))
+		apihelper.SetSessionForRequest(c, &tables.Session{ID: 1, UserID: 42})
+
+		if AuthorizeTenant(c, fakeChecker(rbac.ManageTenants), tenantID) {
+			logger.FromContext(c.Request.Context()).Info("handler")
+		}
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if assert.Equal(t, 1, logs.Len()) {
+		assert.Equal(t, tenantID.String(), logs.All()[0].ContextMap()["tenant_id"], "authorized tenant of user is logged")
+	}
+}
+
+// usersChecker - rights of users by id.
+type usersChecker map[int]rbac.Rights
+
+func (f usersChecker) HasPermission(_ context.Context, userID int, p rbac.Permission) (bool, error) {
+	return f[userID].Has(p), nil
+}
+
+func TestRequireTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const admin, viewer = 1, 2
+
+	tenantID := uuid.New()
+	checker := usersChecker{admin: rbac.ViewUsage | rbac.ManageTenants, viewer: rbac.ViewUsage}
+
+	e := gin.New()
+	e.Use(ErrorMiddleware(), func(c *gin.Context) {
+		if userID, err := strconv.Atoi(c.GetHeader("X-User")); err == nil {
+			apihelper.SetSessionForRequest(c, &tables.Session{ID: userID, UserID: userID})
+		}
+	})
+	e.GET("/usage", RequireTenant(checker), CacheMiddleware(cache.NewLRU(cache.LRUConfig{}), CacheRule{TTL: time.Minute}),
+		func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"tenant_id": RequestTenant(c)}) })
+
+	do := func(user int, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/usage"+query, nil)
+		r.Header.Set("X-User", strconv.Itoa(user))
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(admin, "").Code, "tenant is required")
+
+	query := "?tenant_id=" + tenantID.String()
+
+	w := do(admin, query)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get(CacheStatusHeader))
+	assert.Equal(t, "HIT", do(admin, query).Header().Get(CacheStatusHeader))
+
+	w = do(viewer, query)
+	assert.Equal(t, http.StatusForbidden, w.Code, "cached response doesn't skip authorization")
+	assert.Empty(t, w.Header().Get(CacheStatusHeader))
+}
+
+func TestRequestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyTenant, queryTenant, bodyTenant := uuid.New(), uuid.New(), uuid.New()
+	body := `{"name":"x","tenant_id":"` + bodyTenant.String() + `"}`
+
+	for _, tc := range []struct {
+		name        string
+		keyOf       uuid.UUID
+		query       string
+		contentType string
+		tenant      uuid.UUID
+	}{
+		{name: "key", keyOf: keyTenant, query: queryTenant.String(), contentType: "application/json", tenant: keyTenant},
+		{name: "query", query: queryTenant.String(), contentType: "application/json", tenant: queryTenant},
+		{name: "body", contentType: "application/json; charset=utf-8", tenant: bodyTenant},
+		{name: "not json body", contentType: "text/plain", tenant: uuid.Nil},
+	} {
+		e := gin.New()
+		e.POST("/", func(c *gin.Context) {
+			if tc.keyOf != uuid.Nil {
+				apihelper.SetTenantUUIDForRequest(c, tc.keyOf)
+			}
+
+			assert.Equal(t, tc.tenant, RequestTenant(c), tc.name)
+			assert.Equal(t, tc.tenant, RequestTenant(c), tc.name)
+
+			b, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, string(b), "body is restored")
+		})
+
+		r := httptest.NewRequest(http.MethodPost, "/?tenant_id="+tc.query, strings.NewReader(body))
+		r.Header.Set("Content-Type", tc.contentType)
+		e.ServeHTTP(httptest.NewRecorder(), r)
+	}
+}
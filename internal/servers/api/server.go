@@ -31,11 +31,25 @@ type (
 		Addr           string
 		DisableAuth    bool
 		EnableStatsViz bool
-		AllowOrigin    string
+		CORS           mw.CORSConfig
 		WriteTimeout   time.Duration
 		ReadTimeout    time.Duration
 	}
 
+	// Controller - group of http handlers, which register own routes.
+	// Public routes are available without authentication, private are behind Deps.AuthMiddlewares.
+	Controller interface {
+		Register(public *gin.RouterGroup, private *gin.RouterGroup)
+	}
+
+	// Deps - dependencies of http API server (services based middlewares and controllers).
+	Deps struct {
+		EngineMiddlewares []gin.HandlerFunc // applied for all routes (incl. `/health`), right after recovery.
+		AuthMiddlewares   []gin.HandlerFunc // applied for private `/api/v1/` routes only, skipped if DisableAuth.
+		Middlewares       []gin.HandlerFunc // applied for all `/api/v1/` routes, after auth for private ones.
+		Controllers       []Controller
+	}
+
 	// Server - http API server structure.
 	Server struct {
 		server    *http.Server
@@ -45,7 +59,7 @@ type (
 )
 
 // NewServer - constructor http API Server.
-func NewServer(cfg Config, e *Engine, log *logger.Logger) *Server {
+func NewServer(cfg Config, e *Engine, log *logger.Logger, deps Deps) *Server {
 	if !cfg.IsDevEnv {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -65,6 +79,9 @@ func NewServer(cfg Config, e *Engine, log *logger.Logger) *Server {
 	// Setup main middleware
 	log.Info("Starting create middleware and routes for gin server")
 
+	// Request id must be first, all middlewares below log it.
+	e.Use(mw.RequestIDMiddleware())
+
 	// Add a ginzap middleware, which:
 	//   - Logs all requests, like a combined access and error log.
 	//   - Logs to stdout.
@@ -78,13 +95,19 @@ func NewServer(cfg Config, e *Engine, log *logger.Logger) *Server {
 	//   - stack means whether output the stack info.
 	e.Use(mw.RecoveryWithZap(log, true))
 
-	// https://stackoverflow.com/questions/29418478/go-gin-framework-cors
-	e.Use(mw.CORSMiddleware(cfg.AllowOrigin))
+	// Errors attached via c.Error -> `application/problem+json` responses, logs and metrics.
+	e.Use(mw.ErrorMiddleware())
 
-	e.Use(mw.UUIDMiddleware())
+	e.Use(deps.EngineMiddlewares...)
+
+	// https://stackoverflow.com/questions/29418478/go-gin-framework-cors
+	e.Use(mw.CORSMiddleware(cfg.CORS))
 
 	e.Use(otelgin.Middleware(cfg.ServiceName))
 
+	// Request-scoped logger, @see logger.FromContext.
+	e.Use(mw.ContextLoggerMiddleware(log))
+
 	// add statsviz (viewer of pprof) @see more here -> https://github.com/arl/statsviz
 	if cfg.IsDevEnv {
 		// Create statsviz server.
@@ -113,7 +136,19 @@ func NewServer(cfg Config, e *Engine, log *logger.Logger) *Server {
 
 	apiV1 := e.Group("/api/v1/")
 
-	apiV1 = apiV1
+	// public and private are siblings, so common middlewares run after auth and can use identity of client.
+	public := apiV1.Group("")
+	public.Use(deps.Middlewares...)
+
+	private := apiV1.Group("")
+	if !cfg.DisableAuth {
+		private.Use(deps.AuthMiddlewares...)
+	}
+	private.Use(deps.Middlewares...)
+
+	for _, ctrl := range deps.Controllers {
+		ctrl.Register(public, private)
+	}
 
 	return s
 }
@@ -150,7 +185,7 @@ func DisablingWrapHandler(h *webdav.Handler, isDisabled bool) gin.HandlerFunc {
 		}
 	}
 
-	return ginSwagger.WrapHandler(h)
+	return ginSwagger.WrapHandler(h, ginSwagger.InstanceName(swaggerInstance))
 }
 
 // Health godoc
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/swaggo/swag"
+
+	"github.com/imperiuse/go-app-skeleton/docs"
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+// swaggerInstance - swag instance name of swagger docs with generated problem types definitions.
+const swaggerInstance = "swagger_with_problems"
+
+// problemsSwagger - swagger docs (generated by swag) + definitions `problems.<code>` of all declared problem types.
+type problemsSwagger struct {
+	base swag.Swagger
+}
+
+func init() {
+	swag.Register(swaggerInstance, problemsSwagger{base: docs.SwaggerInfo})
+}
+
+// ReadDoc - implements swag.Swagger.
+func (s problemsSwagger) ReadDoc() string {
+	doc := s.base.ReadDoc()
+
+	var spec map[string]any
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		return doc
+	}
+
+	definitions, _ := spec["definitions"].(map[string]any)
+	if definitions == nil {
+		definitions = make(map[string]any)
+	}
+
+	for k, v := range problemDefinitions() {
+		definitions[k] = v
+	}
+
+	spec["definitions"] = definitions
+
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return doc
+	}
+
+	return string(b)
+}
+
+func problemDefinitions() map[string]any {
+	types := apperror.ProblemTypes()
+
+	result := make(map[string]any, len(types))
+	for _, p := range types {
+		result["problems."+p.Code] = map[string]any{
+			"type":        "object",
+			"description": p.Description,
+			"required":    []string{"type", "title", "status"},
+			"properties": map[string]any{
+				"type":       map[string]any{"type": "string", "enum": []string{apierror.TypePrefix + p.Code}},
+				"title":      map[string]any{"type": "string", "enum": []string{p.Title}},
+				"status":     map[string]any{"type": "integer", "enum": []int{p.Status}},
+				"detail":     map[string]any{"type": "string"},
+				"instance":   map[string]any{"type": "string"},
+				"request_id": map[string]any{"type": "string"},
+			},
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,294 @@
+// Package validation - declarative binding of requests into structs and validation by go-playground validator.
+//
+// Struct fields are bound from path params (`uri` tag), query params (`form` tag) and JSON body (`json` tag)
+// and validated by `binding` tags, e.g.:
+//
+//	type Request struct {
+//		ID    int    `uri:"id" binding:"required,gt=0"`
+//		Limit int    `form:"limit" binding:"omitempty,max=100"`
+//		Name  string `json:"name" binding:"required,max=64"`
+//	}
+//
+// All invalid fields are reported at once in `invalid-params` member of problem details:
+// malformed request (bad JSON, wrong types) - 400, invalid values - 422.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/servers/api/controller/apierror"
+)
+
+// InvalidParamsMember - name of problem details extension member with list of InvalidParam.
+const InvalidParamsMember = "invalid-params"
+
+const (
+	InPath  Location = "path"
+	InQuery Location = "query"
+	InBody  Location = "body"
+)
+
+type (
+	// Location - location of request param.
+	Location string
+
+	// InvalidParam - invalid param of request.
+	InvalidParam struct {
+		Name    string   `json:"name" example:"scopes[0]"`
+		In      Location `json:"in" example:"body"`
+		Pointer string   `json:"pointer,omitempty" example:"/scopes/0"` // JSON pointer (RFC-6901), only for body.
+		Rule    string   `json:"rule" example:"required"`
+		Message string   `json:"message" example:"is required"`
+	}
+)
+
+func init() {
+	registerTagName()
+}
+
+// Bind - bind path params, query params and JSON body (if any) into obj and validate it.
+// If request is not valid, request is aborted with problem details and false is returned.
+func Bind(c *gin.Context, obj any) bool {
+	return bind(c, obj, InPath, InQuery, InBody)
+}
+
+// BindJSON - bind JSON body into obj and validate it, @see Bind.
+func BindJSON(c *gin.Context, obj any) bool {
+	return bind(c, obj, InBody)
+}
+
+// BindQuery - bind query params into obj and validate it, @see Bind.
+func BindQuery(c *gin.Context, obj any) bool {
+	return bind(c, obj, InQuery)
+}
+
+// BindURI - bind path params into obj and validate it, @see Bind.
+func BindURI(c *gin.Context, obj any) bool {
+	return bind(c, obj, InPath)
+}
+
+// BindValues - bind values (e.g. url.Values of query) into obj by `form` tags and validate it.
+// It doesn't depend on transport (gin), so can be used by any handler. Returns nil or apperror.
+func BindValues(values map[string][]string, obj any) error {
+	if err := binding.MapFormWithTag(obj, values, "form"); err != nil {
+		return apperror.ErrBadQueryParam.WithDetail(err.Error())
+	}
+
+	return Validate(obj)
+}
+
+// Validate - validate obj, returns nil or apperror with `invalid-params`.
+func Validate(obj any) error {
+	if err := binding.Validator.ValidateStruct(obj); err != nil {
+		var ve validator.ValidationErrors
+		if !errors.As(err, &ve) {
+			return apperror.BadRequest(err.Error())
+		}
+
+		params := make([]InvalidParam, 0, len(ve))
+		for _, fe := range ve {
+			params = append(params, invalidParam(obj, fe))
+		}
+
+		return apperror.ErrValidation.
+			WithDetailf("%d invalid param(s)", len(params)).
+			With(InvalidParamsMember, params)
+	}
+
+	return nil
+}
+
+func bind(c *gin.Context, obj any, sources ...Location) bool {
+	if err := decode(c, obj, sources); err != nil {
+		apierror.Abort(c, err)
+
+		return false
+	}
+
+	if err := Validate(obj); err != nil {
+		apierror.Abort(c, err)
+
+		return false
+	}
+
+	return true
+}
+
+func decode(c *gin.Context, obj any, sources []Location) error {
+	for _, in := range sources {
+		switch in {
+		case InPath:
+			params := make(map[string][]string, len(c.Params))
+			for _, p := range c.Params {
+				params[p.Key] = []string{p.Value}
+			}
+
+			if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+				return apperror.ErrBadPathParam.WithDetail(err.Error())
+			}
+		case InQuery:
+			if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+				return apperror.ErrBadQueryParam.WithDetail(err.Error())
+			}
+		case InBody:
+			if err := decodeJSON(c.Request, obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func decodeJSON(r *http.Request, obj any) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	err := json.NewDecoder(r.Body).Decode(obj)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperror.ErrPayloadTooLarge.WithDetailf("request body is larger than %d bytes", tooLarge.Limit)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apperror.BadRequest("request body has invalid types").With(InvalidParamsMember, []InvalidParam{{
+			Name:    typeErr.Field,
+			In:      InBody,
+			Pointer: "/" + strings.ReplaceAll(typeErr.Field, ".", "/"),
+			Rule:    "type",
+			Message: "must be " + typeErr.Type.String(),
+		}})
+	}
+
+	return apperror.BadRequest("request body is not valid JSON: " + err.Error())
+}
+
+func invalidParam(obj any, fe validator.FieldError) InvalidParam {
+	// Namespace() is `Root.field.sub[0]` with names from tags, @see registerTagName.
+	name := fe.Namespace()
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	p := InvalidParam{
+		Name:    name,
+		In:      location(obj, fe.StructNamespace()),
+		Rule:    fe.Tag(),
+		Message: message(fe),
+	}
+
+	if p.In == InBody {
+		pointer := strings.NewReplacer(".", "/", "[", "/", "]", "").Replace(name)
+		p.Pointer = "/" + pointer
+	}
+
+	return p
+}
+
+// location - location of top level struct field of namespace.
+func location(obj any, structNamespace string) Location {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	parts := strings.SplitN(structNamespace, ".", 3) //nolint: gomnd // Root.Field.rest.
+	if t.Kind() != reflect.Struct || len(parts) < 2 {
+		return InBody
+	}
+
+	f, ok := t.FieldByName(strings.SplitN(parts[1], "[", 2)[0])
+	if !ok {
+		return InBody
+	}
+
+	switch {
+	case f.Tag.Get("uri") != "":
+		return InPath
+	case f.Tag.Get("form") != "":
+		return InQuery
+	default:
+		return InBody
+	}
+}
+
+func message(fe validator.FieldError) string {
+	param := fe.Param()
+	isLen := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice ||
+		fe.Kind() == reflect.Array || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_with", "required_without":
+		return "is required"
+	case "min", "gte":
+		if isLen {
+			return "length must be at least " + param
+		}
+
+		return "must be greater than or equal to " + param
+	case "max", "lte":
+		if isLen {
+			return "length must be at most " + param
+		}
+
+		return "must be less than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "len":
+		return "length must be " + param
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "email":
+		return "must be a valid email"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "url", "uri":
+		return "must be a valid URL"
+	case "datetime":
+		return "must be a time in format " + param
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+// registerTagName - names of fields in validation errors are taken from `json`, `form` or `uri` tags.
+func registerTagName() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0] //nolint: gomnd // name,options.
+			if name == "-" {
+				return ""
+			}
+
+			if name != "" {
+				return name
+			}
+		}
+
+		return f.Name
+	})
+}
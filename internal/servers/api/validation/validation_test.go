@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	item struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	request struct {
+		ID    int    `uri:"id" binding:"gt=0"`
+		Limit int    `form:"limit" binding:"omitempty,max=100"`
+		Email string `json:"email" binding:"required,email"`
+		Items []item `json:"items" binding:"required,min=1,dive"`
+	}
+)
+
+func TestBind(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.POST("/items/:id", func(c *gin.Context) {
+		var req request
+		if !Bind(c, &req) {
+			return
+		}
+
+		c.JSON(http.StatusOK, req)
+	})
+
+	do := func(path string, body string) (*httptest.ResponseRecorder, map[string]any) {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return w, resp
+	}
+
+	w, resp := do("/items/1?limit=10", `{"email":"a@b.c","items":[{"name":"x"}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 10, resp["Limit"])
+
+	w, resp = do("/items/0?limit=1000", `{"email":"bad","items":[{"name":""}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	params, _ := resp[InvalidParamsMember].([]any)
+	require.Len(t, params, 4, "all invalid fields are reported")
+
+	expected := []map[string]any{
+		{"name": "id", "in": "path", "rule": "gt", "message": "must be greater than 0"},
+		{"name": "limit", "in": "query", "rule": "max", "message": "must be less than or equal to 100"},
+		{"name": "email", "in": "body", "pointer": "/email", "rule": "email", "message": "must be a valid email"},
+		{"name": "items[0].name", "in": "body", "pointer": "/items/0/name", "rule": "required", "message": "is required"},
+	}
+	for i, p := range params {
+		assert.Equal(t, expected[i], p)
+	}
+
+	w, resp = do("/items/1", `{"email":1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, resp[InvalidParamsMember], 1)
+
+	w, _ = do("/items/1", `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = do("/items/x", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
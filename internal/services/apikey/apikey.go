@@ -0,0 +1,198 @@
+// Package apikey - api keys for machine clients (services), stored hashed in `api_keys` table.
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/filter"
+	"github.com/imperiuse/go-app-skeleton/internal/database/fulltext"
+	"github.com/imperiuse/go-app-skeleton/internal/database/pagination"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tenancy"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+const (
+	// lastUsedPrecision - last_used_at is updated not often than this, so validation is not a write on every request.
+	lastUsedPrecision = time.Minute
+
+	// createAttempts - key is generated again if its prefix is already used (unique index of prefix).
+	createAttempts = 3
+)
+
+var (
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrInvalidKey    = errors.New("api key is invalid, expired or revoked")
+	ErrEmptyScopes   = errors.New("api key must have at least one scope")
+	ErrExpiredInPast = errors.New("api key expiration must be in future")
+)
+
+type (
+	// Service - api keys service.
+	Service struct {
+		db *database.DB
+
+		now func() time.Time
+	}
+
+	// ListParams - params of api keys list.
+	ListParams struct {
+		Filter filter.Filter
+		Search fulltext.Query
+		Page   pagination.Request
+	}
+
+	// Hit - api key of list with full-text search rank and highlighted name (zero values without search).
+	Hit struct {
+		tables.APIKey `gorm:"embedded"`
+
+		Rank      float64 `gorm:"column:rank;->"`
+		Highlight string  `gorm:"column:highlight;->"`
+	}
+
+	// CreateParams - params of new api key.
+	CreateParams struct {
+		TenantID  uuid.UUID
+		CreatedBy int
+		Name      string
+		Scopes    rbac.Rights
+		ExpiredAt *time.Time
+	}
+)
+
+// New - constructor of api keys Service.
+func New(db *database.DB) *Service {
+	return &Service{db: db, now: time.Now}
+}
+
+// Create - create new api key. Returned key value must be shown to client only once.
+func (s *Service) Create(ctx context.Context, p CreateParams) (string, *tables.APIKey, error) {
+	if p.Scopes == 0 {
+		return "", nil, ErrEmptyScopes
+	}
+
+	now := s.now().UTC()
+	if p.ExpiredAt != nil && !p.ExpiredAt.After(now) {
+		return "", nil, ErrExpiredInPast
+	}
+
+	for attempt := 1; ; attempt++ {
+		k, err := newRawKey()
+		if err != nil {
+			return "", nil, err
+		}
+
+		key := &tables.APIKey{
+			CreatedAt: now,
+			UpdatedAt: now,
+			TenantID:  p.TenantID,
+			CreatedBy: p.CreatedBy,
+			Name:      p.Name,
+			Prefix:    k.prefix,
+			HSecret:   k.hashedSecret(),
+			Rights:    int16(p.Scopes),
+			ExpiredAt: p.ExpiredAt,
+		}
+
+		err = s.db.WithContext(tenancy.WithTenant(ctx, p.TenantID)).Create(key).Error
+		if err == nil {
+			return k.String(), key, nil
+		}
+
+		if !database.IsUniqueViolation(err) || attempt == createAttempts {
+			return "", nil, fmt.Errorf("create api key: %w", err)
+		}
+	}
+}
+
+// Validate - check api key, returns key record if it's valid, not expired and not revoked.
+func (s *Service) Validate(ctx context.Context, value string) (*tables.APIKey, error) {
+	k, err := parseRawKey(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// tenant is unknown before auth, key is found by prefix among keys of all tenants.
+	ctx = tenancy.Elevate(ctx)
+
+	key := &tables.APIKey{}
+	if err = s.db.WithContext(ctx).Where("prefix = ?", k.prefix).Take(key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+
+		return nil, fmt.Errorf("find api key: %w", err)
+	}
+
+	now := s.now().UTC()
+	if !k.verify(key.HSecret) || key.RevokedAt != nil || (key.ExpiredAt != nil && !key.ExpiredAt.After(now)) {
+		return nil, ErrInvalidKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedPrecision {
+		key.LastUsedAt = &now
+		if err = s.db.WithContext(ctx).Model(key).UpdateColumn("last_used_at", now).Error; err != nil {
+			// not critical for auth, only log.
+			logger.FromContext(ctx).Warn("api key update last_used_at", field.ID(key.ID), field.Error(err))
+		}
+	}
+
+	return key, nil
+}
+
+// List - page of api keys of tenant (including revoked and expired) matched by filter and search,
+// @see pagination.Find.
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, p ListParams) (pagination.Page[Hit], error) {
+	db := s.db.WithContext(tenancy.WithTenant(ctx, tenantID)).
+		Scopes(p.Filter.Scope, tables.APIKeySearch.Scope(p.Search))
+
+	if !p.Search.IsZero() {
+		db = db.Select("*, ? AS rank, ? AS highlight",
+			tables.APIKeySearch.Rank(p.Search), tables.APIKeySearch.Headline("name", p.Search))
+	}
+
+	page, err := pagination.Find[Hit](db, p.Page)
+	if err != nil {
+		return page, fmt.Errorf("list api keys: %w", err)
+	}
+
+	return page, nil
+}
+
+// Revoke - revoke api key of tenant.
+func (s *Service) Revoke(ctx context.Context, tenantID uuid.UUID, keyID int) error {
+	now := s.now().UTC()
+
+	// RETURNING fills key, so it is re-indexed with revoked_at, @see search.Plugin.
+	var key tables.APIKey
+
+	res := s.db.WithContext(tenancy.WithTenant(ctx, tenantID)).
+		Model(&key).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Updates(map[string]any{"revoked_at": now, "updated_at": now})
+	if res.Error != nil {
+		return fmt.Errorf("revoke api key: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// TableName - table name.
+func (Hit) TableName() string {
+	return tables.APIKey{}.TableName()
+}
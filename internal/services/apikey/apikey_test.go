@@ -0,0 +1,54 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/services/rbac"
+)
+
+// pgError - postgres error with code, @see database.IsUniqueViolation.
+type pgError string
+
+func (e pgError) Error() string { return "pg error " + string(e) }
+
+func (e pgError) SQLState() string { return string(e) }
+
+func TestService_Create_PrefixCollision(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	var prefixes []string
+
+	collisions := 1
+
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:unique", func(tx *gorm.DB) {
+		prefixes = append(prefixes, tx.Statement.Dest.(*tables.APIKey).Prefix) //nolint:forcetypeassert // api key.
+
+		if collisions > 0 {
+			collisions--
+			_ = tx.AddError(pgError("23505"))
+		}
+	}))
+
+	s := New(&database.DB{DB: db})
+
+	value, key, err := s.Create(context.Background(), CreateParams{TenantID: uuid.New(), Scopes: rbac.ReadReports})
+	require.NoError(t, err)
+	require.Len(t, prefixes, 2, "key is generated again")
+	assert.NotEqual(t, prefixes[0], prefixes[1])
+	assert.Equal(t, prefixes[1], key.Prefix)
+	assert.Contains(t, value, key.Prefix)
+
+	collisions = createAttempts
+
+	_, _, err = s.Create(context.Background(), CreateParams{TenantID: uuid.New(), Scopes: rbac.ReadReports})
+	require.ErrorIs(t, err, pgError("23505"))
+}
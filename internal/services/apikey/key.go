@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	keyPrefix      = "rsk_" // reports service key, helps secret scanners (e.g. gitleaks) to find leaked keys.
+	prefixIDBytes  = 4      // hex -> 8 symbols, prefix fits `api_keys.prefix CHAR(12)`.
+	secretBytes    = 32
+	keySeparator   = "."
+	prefixFullSize = len(keyPrefix) + prefixIDBytes*2
+)
+
+var ErrMalformedKey = errors.New("malformed api key")
+
+// rawKey - api key, visible prefix is stored as is, secret - only sha256 hash.
+type rawKey struct {
+	prefix string
+	secret string
+}
+
+func newRawKey() (rawKey, error) {
+	id := make([]byte, prefixIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return rawKey{}, fmt.Errorf("rand.Read prefix: %w", err)
+	}
+
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return rawKey{}, fmt.Errorf("rand.Read secret: %w", err)
+	}
+
+	return rawKey{
+		prefix: keyPrefix + hex.EncodeToString(id),
+		secret: base64.RawURLEncoding.EncodeToString(secret),
+	}, nil
+}
+
+func parseRawKey(key string) (rawKey, error) {
+	prefix, secret, found := strings.Cut(key, keySeparator)
+	if !found || len(prefix) != prefixFullSize || !strings.HasPrefix(prefix, keyPrefix) || secret == "" {
+		return rawKey{}, ErrMalformedKey
+	}
+
+	return rawKey{prefix: prefix, secret: secret}, nil
+}
+
+// String - full key value, given to client only once.
+func (k rawKey) String() string {
+	return k.prefix + keySeparator + k.secret
+}
+
+func (k rawKey) hashedSecret() string {
+	h := sha256.Sum256([]byte(k.secret))
+
+	return hex.EncodeToString(h[:])
+}
+
+// verify - constant time compare of secret with stored hash.
+func (k rawKey) verify(hSecret string) bool {
+	return subtle.ConstantTimeCompare([]byte(k.hashedSecret()), []byte(strings.TrimSpace(hSecret))) == 1
+}
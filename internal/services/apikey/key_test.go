@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawKey(t *testing.T) {
+	k, err := newRawKey()
+	require.NoError(t, err)
+
+	assert.Len(t, k.prefix, 12)
+	assert.Len(t, k.hashedSecret(), 64)
+
+	parsed, err := parseRawKey(k.String())
+	require.NoError(t, err)
+	assert.Equal(t, k, parsed)
+	assert.True(t, parsed.verify(k.hashedSecret()))
+
+	other, err := newRawKey()
+	require.NoError(t, err)
+	assert.False(t, parsed.verify(other.hashedSecret()))
+
+	for _, key := range []string{"", "rsk_0123abcd", "rsk_0123abcd.", "abc_0123abcd.secret", "rsk_012.secret"} {
+		_, err = parseRawKey(key)
+		assert.ErrorIs(t, err, ErrMalformedKey, key)
+	}
+}
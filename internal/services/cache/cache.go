@@ -0,0 +1,205 @@
+// Package cache - storage of cached http responses with invalidation by tags, @see middleware.CacheMiddleware.
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxEntries = 10_000
+	defaultMaxBytes   = 64 << 20
+
+	// entryOverhead - approximate size of entry without key, headers and body.
+	entryOverhead = 128
+)
+
+type (
+	// Entry - cached response.
+	Entry struct {
+		Status    int
+		Header    http.Header
+		Body      []byte
+		Tags      []string  // entry is dropped by invalidation of any of them.
+		StoredAt  time.Time // start of response computation: entry is stale if its tags are invalidated after it.
+		ExpiredAt time.Time
+	}
+
+	// Store - pluggable storage of entries.
+	Store interface {
+		// Get - not expired entry by key.
+		Get(key string) (*Entry, bool)
+		// Set - store entry, unless it's invalidated while it was computed.
+		Set(key string, e *Entry)
+		// InvalidateTags - drop entries with any of tags.
+		InvalidateTags(tags ...string)
+	}
+
+	// LRUConfig - size limits of LRU.
+	LRUConfig struct {
+		MaxEntries int
+		MaxBytes   int // approximate size of keys, headers and bodies.
+	}
+
+	// LRU - in-memory storage of replica, least recently used entries are evicted over size limits.
+	// NB! Invalidation is local: other replicas serve stale entries until they are expired.
+	LRU struct {
+		cfg LRUConfig
+
+		mu               sync.Mutex
+		items            map[string]*list.Element
+		order            *list.List // front - most recently used.
+		tags             map[string]map[string]struct{}
+		bytes            int
+		lastInvalidation time.Time
+
+		now func() time.Time
+	}
+
+	item struct {
+		key   string
+		entry *Entry
+		size  int
+	}
+)
+
+// TenantTag - tag of responses of tenant, they are invalidated by changes of tenant.
+func TenantTag(tenantID uuid.UUID) string {
+	return "tenant:" + tenantID.String()
+}
+
+// NewLRU - constructor of LRU.
+func NewLRU(cfg LRUConfig) *LRU {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultMaxEntries
+	}
+
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultMaxBytes
+	}
+
+	return &LRU{
+		cfg:   cfg,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		tags:  make(map[string]map[string]struct{}),
+		now:   time.Now,
+	}
+}
+
+// Get - implements Store.
+func (l *LRU) Get(key string) (*Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	it := el.Value.(*item) //nolint:forcetypeassert // only items are in list.
+	if !l.now().Before(it.entry.ExpiredAt) {
+		l.remove(el)
+
+		return nil, false
+	}
+
+	l.order.MoveToFront(el)
+
+	return it.entry, true
+}
+
+// Set - implements Store. Entry bigger than MaxBytes isn't stored.
+func (l *LRU) Set(key string, e *Entry) {
+	size := entrySize(key, e)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if size > l.cfg.MaxBytes || e.StoredAt.Before(l.lastInvalidation) {
+		return
+	}
+
+	if el, ok := l.items[key]; ok {
+		l.remove(el)
+	}
+
+	it := &item{key: key, entry: e, size: size}
+	l.items[key] = l.order.PushFront(it)
+	l.bytes += size
+
+	for _, tag := range e.Tags {
+		keys, ok := l.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			l.tags[tag] = keys
+		}
+
+		keys[key] = struct{}{}
+	}
+
+	for len(l.items) > l.cfg.MaxEntries || l.bytes > l.cfg.MaxBytes {
+		l.remove(l.order.Back())
+	}
+}
+
+// InvalidateTags - implements Store. Responses which are computed at the moment aren't stored too.
+func (l *LRU) InvalidateTags(tags ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastInvalidation = l.now()
+
+	for _, tag := range tags {
+		for key := range l.tags[tag] {
+			l.remove(l.items[key])
+		}
+	}
+}
+
+// Len - number of entries.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.items)
+}
+
+// remove - remove element from list, map and tags index. Must be called under mu.
+func (l *LRU) remove(el *list.Element) {
+	it := el.Value.(*item) //nolint:forcetypeassert // only items are in list.
+
+	l.order.Remove(el)
+	delete(l.items, it.key)
+	l.bytes -= it.size
+
+	for _, tag := range it.entry.Tags {
+		if keys, ok := l.tags[tag]; ok {
+			delete(keys, it.key)
+
+			if len(keys) == 0 {
+				delete(l.tags, tag)
+			}
+		}
+	}
+}
+
+func entrySize(key string, e *Entry) int {
+	size := entryOverhead + len(key) + len(e.Body)
+	for k, values := range e.Header {
+		size += len(k)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+
+	for _, tag := range e.Tags {
+		size += len(tag)
+	}
+
+	return size
+}
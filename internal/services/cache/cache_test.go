@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	l := NewLRU(LRUConfig{MaxEntries: 2})
+	l.now = func() time.Time { return now }
+
+	entry := func(tags ...string) *Entry {
+		return &Entry{Status: http.StatusOK, Body: []byte("body"), Tags: tags, StoredAt: now, ExpiredAt: now.Add(time.Minute)}
+	}
+
+	l.Set("a", entry("t1"))
+	l.Set("b", entry("t2"))
+
+	_, ok := l.Get("a") // a is recently used now.
+	require.True(t, ok)
+
+	l.Set("c", entry("t1"))
+
+	_, ok = l.Get("b")
+	assert.False(t, ok, "least recently used is evicted")
+	assert.Equal(t, 2, l.Len())
+
+	l.InvalidateTags("t1")
+	assert.Equal(t, 0, l.Len())
+
+	l.Set("d", &Entry{StoredAt: now.Add(-time.Second), ExpiredAt: now.Add(time.Minute)})
+	assert.Equal(t, 0, l.Len(), "entry computed before invalidation isn't stored")
+
+	now = now.Add(time.Second)
+	l.Set("e", entry())
+
+	now = now.Add(time.Minute)
+	_, ok = l.Get("e")
+	assert.False(t, ok, "expired")
+	assert.Equal(t, 0, l.Len())
+}
+
+func TestLRU_MaxBytes(t *testing.T) {
+	l := NewLRU(LRUConfig{MaxBytes: 2 * (entryOverhead + 1 + 100)})
+	now := time.Now()
+
+	for _, key := range []string{"a", "b", "c"} {
+		l.Set(key, &Entry{Body: make([]byte, 100), StoredAt: now, ExpiredAt: now.Add(time.Minute)})
+	}
+
+	assert.Equal(t, 2, l.Len())
+
+	l.Set("big", &Entry{Body: make([]byte, 1000), StoredAt: now, ExpiredAt: now.Add(time.Minute)})
+	_, ok := l.Get("big")
+	assert.False(t, ok, "entry bigger than limit isn't stored")
+	assert.Equal(t, 2, l.Len())
+}
@@ -0,0 +1,179 @@
+// Package concurrency - adaptive (AIMD by observed latency) limiter of in-flight requests with short queue.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialLimit     = 100
+	defaultMinLimit         = 10
+	defaultMaxLimit         = 1000
+	defaultLatencyThreshold = time.Second
+	defaultBackoffRatio     = 0.9
+	defaultQueueTimeout     = 50 * time.Millisecond
+)
+
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+type (
+	// Config - limiter config.
+	Config struct {
+		InitialLimit     int
+		MinLimit         int
+		MaxLimit         int
+		LatencyThreshold time.Duration // latency above threshold is overload signal -> limit decreases.
+		BackoffRatio     float64       // multiplicative decrease ratio (0..1).
+		MaxQueue         int           // how many requests could wait for free slot, 0 - no queue.
+		QueueTimeout     time.Duration // how long request could wait for free slot.
+	}
+
+	// Limiter - AIMD limiter: limit grows by 1 per `limit` successful requests, and
+	// multiplies by BackoffRatio on slow (or dropped) request.
+	Limiter struct {
+		cfg Config
+
+		mu       sync.Mutex
+		limit    float64
+		inFlight int
+		waiters  []chan struct{}
+
+		now func() time.Time
+	}
+
+	// Stats - current state of limiter.
+	Stats struct {
+		Limit    int
+		InFlight int
+		Queued   int
+	}
+
+	// Token - acquired slot, must be released.
+	Token struct {
+		l     *Limiter
+		start time.Time
+	}
+)
+
+// New - constructor of Limiter.
+func New(cfg Config) *Limiter {
+	cfg.MinLimit = orDefault(cfg.MinLimit, defaultMinLimit)
+	cfg.MaxLimit = orDefault(cfg.MaxLimit, defaultMaxLimit)
+	cfg.InitialLimit = orDefault(cfg.InitialLimit, defaultInitialLimit)
+	cfg.InitialLimit = max(cfg.MinLimit, min(cfg.MaxLimit, cfg.InitialLimit))
+
+	if cfg.LatencyThreshold <= 0 {
+		cfg.LatencyThreshold = defaultLatencyThreshold
+	}
+
+	if cfg.BackoffRatio <= 0 || cfg.BackoffRatio >= 1 {
+		cfg.BackoffRatio = defaultBackoffRatio
+	}
+
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = defaultQueueTimeout
+	}
+
+	return &Limiter{cfg: cfg, limit: float64(cfg.InitialLimit), now: time.Now}
+}
+
+// Acquire - take slot. If limit is reached and canWait - request waits in queue for QueueTimeout,
+// otherwise ErrLimitExceeded is returned immediately.
+func (l *Limiter) Acquire(ctx context.Context, canWait bool) (*Token, error) {
+	l.mu.Lock()
+
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+
+		return &Token{l: l, start: l.now()}, nil
+	}
+
+	if !canWait || len(l.waiters) >= l.cfg.MaxQueue {
+		l.mu.Unlock()
+
+		return nil, ErrLimitExceeded
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return &Token{l: l, start: l.now()}, nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	if l.leaveQueue(ready) {
+		return nil, ErrLimitExceeded
+	}
+
+	// slot was handed over concurrently with timeout.
+	return &Token{l: l, start: l.now()}, nil
+}
+
+// Release - free slot and adapt limit. Dropped means request failed because of overload (e.g. timeout).
+func (t *Token) Release(dropped bool) {
+	l := t.l
+	latency := l.now().Sub(t.start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if dropped || latency > l.cfg.LatencyThreshold {
+		l.limit = math.Max(float64(l.cfg.MinLimit), l.limit*l.cfg.BackoffRatio)
+	} else {
+		l.limit = math.Min(float64(l.cfg.MaxLimit), l.limit+1/l.limit)
+	}
+
+	l.inFlight--
+
+	// hand over free slots to waiters (slot is counted as in-flight before waiter wakes up).
+	for len(l.waiters) > 0 && l.inFlight < int(l.limit) {
+		ready := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+		close(ready)
+	}
+}
+
+// Stats - current state of limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{Limit: int(l.limit), InFlight: l.inFlight, Queued: len(l.waiters)}
+}
+
+// leaveQueue - remove waiter from queue, returns false if waiter was already woken up.
+func (l *Limiter) leaveQueue(ready chan struct{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, w := range l.waiters {
+		if w == ready {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func orDefault(v int, def int) int {
+	if v <= 0 {
+		return def
+	}
+
+	return v
+}
@@ -0,0 +1,82 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_AIMD(t *testing.T) {
+	now := time.Now()
+
+	l := New(Config{InitialLimit: 10, MinLimit: 2, MaxLimit: 11, LatencyThreshold: time.Second})
+	l.now = func() time.Time { return now }
+
+	tok, err := l.Acquire(context.Background(), false)
+	require.NoError(t, err)
+	tok.Release(false)
+	assert.Equal(t, 10, l.Stats().Limit, "limit grows by 1 per `limit` requests")
+
+	for i := 0; i < 20; i++ {
+		tok, _ = l.Acquire(context.Background(), false)
+		tok.Release(false)
+	}
+	assert.Equal(t, 11, l.Stats().Limit, "limit is capped by max")
+
+	tok, _ = l.Acquire(context.Background(), false)
+	now = now.Add(2 * time.Second)
+	tok.Release(false)
+	assert.Equal(t, 9, l.Stats().Limit, "slow request decreases limit")
+
+	for i := 0; i < 50; i++ {
+		tok, _ = l.Acquire(context.Background(), false)
+		tok.Release(true)
+	}
+	assert.Equal(t, Stats{Limit: 2}, l.Stats(), "limit is capped by min")
+}
+
+func TestLimiter_Queue(t *testing.T) {
+	l := New(Config{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 1, QueueTimeout: time.Second})
+
+	first, err := l.Acquire(context.Background(), true)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), false)
+	assert.ErrorIs(t, err, ErrLimitExceeded, "low priority is not queued")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		second, err := l.Acquire(context.Background(), true)
+		assert.NoError(t, err)
+		second.Release(false)
+	}()
+
+	require.Eventually(t, func() bool { return l.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	_, err = l.Acquire(context.Background(), true)
+	assert.ErrorIs(t, err, ErrLimitExceeded, "queue is full")
+
+	first.Release(false)
+	wg.Wait()
+
+	assert.Equal(t, Stats{Limit: 1}, l.Stats())
+}
+
+func TestLimiter_QueueTimeout(t *testing.T) {
+	l := New(Config{InitialLimit: 1, MinLimit: 1, MaxLimit: 1, MaxQueue: 1, QueueTimeout: time.Millisecond})
+
+	_, err := l.Acquire(context.Background(), true)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), true)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, Stats{Limit: 1, InFlight: 1}, l.Stats())
+}
@@ -0,0 +1,194 @@
+// Package idempotency - storage of requests with `Idempotency-Key` header and their responses (`idempotency_keys`
+// table), so retries of request get the same response instead of repeated side effects.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+)
+
+const (
+	defaultTTL           = 24 * time.Hour
+	defaultLockTimeout   = time.Minute
+	defaultPurgeInterval = 10 * time.Minute
+
+	// acquireAttempts - key can be purged between failed acquire and lookup, then acquire is repeated.
+	acquireAttempts = 2
+)
+
+// acquireSQL - insert in flight key. Expired key and key abandoned by crashed replica (in flight longer than lock
+// timeout, same request only) are taken over. No rows - key is used by other request.
+const acquireSQL = `
+INSERT INTO idempotency_keys AS k (scope, key, fingerprint, status_code, locked_at, created_at, expired_at)
+VALUES (@scope, @key, @fingerprint, 0, NOW(), NOW(), NOW() + make_interval(secs => @ttl))
+ON CONFLICT (scope, key) DO UPDATE SET
+    fingerprint = EXCLUDED.fingerprint,
+    status_code = 0,
+    headers = NULL,
+    body = NULL,
+    locked_at = NOW(),
+    created_at = NOW(),
+    expired_at = EXCLUDED.expired_at
+WHERE k.expired_at < NOW()
+   OR (k.status_code = 0 AND k.fingerprint = EXCLUDED.fingerprint
+       AND k.locked_at < NOW() - make_interval(secs => @lock_timeout))
+RETURNING k.key`
+
+var (
+	ErrInFlight            = errors.New("request with idempotency key is in flight")
+	ErrFingerprintMismatch = errors.New("idempotency key is used by other request")
+)
+
+type (
+	// Config - idempotency service config.
+	Config struct {
+		TTL           time.Duration // how long responses are kept for replay.
+		LockTimeout   time.Duration // in flight key is taken over by retry after it (replica crashed).
+		PurgeInterval time.Duration // how often expired keys are deleted.
+	}
+
+	// Service - idempotency service.
+	Service struct {
+		cfg Config
+		db  *database.DB
+		log *logger.Logger
+	}
+
+	// Response - stored response of request.
+	Response struct {
+		StatusCode int
+		Header     http.Header
+		Body       []byte
+	}
+)
+
+// New - constructor of idempotency Service.
+func New(cfg Config, db *database.DB, log *logger.Logger) *Service {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaultLockTimeout
+	}
+
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = defaultPurgeInterval
+	}
+
+	return &Service{cfg: cfg, db: db, log: log}
+}
+
+// Begin - lock key for request with fingerprint. Returns stored response if request is already completed (replay),
+// nil response if request must be processed (call Complete or Release after it), ErrInFlight if request is
+// in flight or ErrFingerprintMismatch if key was used by other request.
+func (s *Service) Begin(ctx context.Context, scope string, key string, fingerprint string) (*Response, error) {
+	for i := 0; i < acquireAttempts; i++ {
+		var acquired []string
+
+		// in transaction: rows of tenant context are read only in it, @see tenancy.Config.
+		if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Raw(acquireSQL, map[string]any{
+				"scope":        scope,
+				"key":          key,
+				"fingerprint":  fingerprint,
+				"ttl":          s.cfg.TTL.Seconds(),
+				"lock_timeout": s.cfg.LockTimeout.Seconds(),
+			}).Scan(&acquired).Error
+		}); err != nil {
+			return nil, fmt.Errorf("acquire idempotency key: %w", err)
+		}
+
+		if len(acquired) > 0 {
+			return nil, nil
+		}
+
+		var k tables.IdempotencyKey
+
+		err := s.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).Take(&k).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("get idempotency key: %w", err)
+		}
+
+		switch {
+		case k.Fingerprint != fingerprint:
+			return nil, ErrFingerprintMismatch
+		case k.StatusCode == 0:
+			return nil, ErrInFlight
+		default:
+			return &Response{StatusCode: k.StatusCode, Header: k.Headers, Body: k.Body}, nil
+		}
+	}
+
+	return nil, ErrInFlight
+}
+
+// Complete - store response of request for replay.
+func (s *Service) Complete(ctx context.Context, scope string, key string, resp Response) error {
+	err := s.db.WithContext(ctx).
+		Model(&tables.IdempotencyKey{}).
+		Where("scope = ? AND key = ? AND status_code = 0", scope, key).
+		Updates(&tables.IdempotencyKey{StatusCode: resp.StatusCode, Headers: resp.Header, Body: resp.Body}).Error
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release - unlock key of failed request, so it can be retried.
+func (s *Service) Release(ctx context.Context, scope string, key string) error {
+	if err := s.db.WithContext(ctx).
+		Where("scope = ? AND key = ? AND status_code = 0", scope, key).
+		Delete(&tables.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeExpired - delete expired keys.
+func (s *Service) PurgeExpired(ctx context.Context) (int64, error) {
+	res := s.db.WithContext(ctx).Where("expired_at < NOW()").Delete(&tables.IdempotencyKey{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("purge idempotency keys: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// RunPurger - periodically purge expired keys until ctx is done.
+func (s *Service) RunPurger(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n, err := s.PurgeExpired(ctx)
+			if err != nil {
+				s.log.Error("idempotency keys purger", field.Error(err))
+
+				continue
+			}
+
+			s.log.Debug("idempotency keys purger", field.Int64("purged", n))
+		}
+	}
+}
@@ -0,0 +1,357 @@
+// Package metering - usage of tenants (requests, generated reports, storage bytes): hourly and daily aggregates
+// in Postgres, quotas with soft and hard limits and per-tenant Prometheus counters.
+//
+// Usage is counted in memory and flushed to DB periodically, so usage of other replicas is visible with delay
+// (FlushInterval + RefreshInterval) and quotas are approximate.
+package metering
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tenancy"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+)
+
+const (
+	defaultFlushInterval   = 10 * time.Second
+	defaultRefreshInterval = 10 * time.Second
+	defaultMaxTenantLabels = 100
+	finalFlushTimeout      = 5 * time.Second
+
+	// otherTenantsLabel - label of tenants over MaxTenantLabels.
+	otherTenantsLabel = "other"
+)
+
+// Metric - metered resource.
+type Metric string
+
+const (
+	Requests     Metric = "requests"
+	Reports      Metric = "reports"       // generated reports.
+	StorageBytes Metric = "storage_bytes" // delta of stored bytes, may be negative.
+)
+
+// Period - aggregation period of usage.
+type Period string
+
+const (
+	Hour Period = "hour"
+	Day  Period = "day"
+)
+
+// Periods - all aggregation periods.
+var Periods = [...]Period{Hour, Day}
+
+type (
+	// Config - settings of metering Service.
+	Config struct {
+		FlushInterval   time.Duration // how often usage is written to DB.
+		RefreshInterval time.Duration // how often usage of other replicas is loaded from DB for quota checks.
+		MaxTenantLabels int           // max tenants with own `tenant` label in Prometheus.
+		Quotas          []Quota       // default quotas of all tenants.
+	}
+
+	// LimitsProvider - per-tenant overrides of quotas, @see services/tenant.Service and Quota.
+	LimitsProvider interface {
+		Settings(ctx context.Context, id uuid.UUID) (tables.TenantSettings, error)
+	}
+
+	// Service - metering service.
+	Service struct {
+		cfg    Config
+		db     *database.DB
+		limits LimitsProvider
+		log    *logger.Logger
+
+		mu       sync.Mutex
+		counters map[counterKey]*counter
+		labels   map[uuid.UUID]string
+		flushing bool   // flush is in flight: its rows may or may not be in DB yet.
+		flushes  uint64 // number of started flushes.
+
+		now func() time.Time
+	}
+
+	counterKey struct {
+		tenantID uuid.UUID
+		metric   Metric
+		period   Period
+		bucket   time.Time
+	}
+
+	// counter - usage of bucket: loaded from DB + flushed by this replica after load + not flushed yet.
+	counter struct {
+		loaded   int64
+		loadedAt time.Time
+		flushed  int64
+		pending  int64
+	}
+)
+
+// New - constructor of metering Service, call Run to flush usage to DB.
+func New(cfg Config, db *database.DB, limits LimitsProvider, log *logger.Logger) *Service {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+
+	if cfg.MaxTenantLabels <= 0 {
+		cfg.MaxTenantLabels = defaultMaxTenantLabels
+	}
+
+	return &Service{
+		cfg:      cfg,
+		db:       db,
+		limits:   limits,
+		log:      log,
+		counters: make(map[counterKey]*counter),
+		labels:   make(map[uuid.UUID]string),
+		now:      time.Now,
+	}
+}
+
+// Start - start of period which contains t (UTC).
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	if p == Hour {
+		return t.Truncate(time.Hour)
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// End - end (exclusive) of period which contains t.
+func (p Period) End(t time.Time) time.Time {
+	if p == Hour {
+		return p.Start(t).Add(time.Hour)
+	}
+
+	return p.Start(t).AddDate(0, 0, 1)
+}
+
+// Record - add n units of metric to usage of tenant. It never blocks on DB.
+func (s *Service) Record(tenantID uuid.UUID, metric Metric, n int64) {
+	if n == 0 || tenantID == uuid.Nil {
+		return
+	}
+
+	s.mu.Lock()
+	label := s.add(tenantID, metric, n, s.now())
+	s.mu.Unlock()
+
+	if n > 0 { // prometheus counter can't be decreased (storage bytes are freed).
+		metrics.UsageAdd(label, string(metric), float64(n))
+	}
+}
+
+// add - add n units to pending usage of all periods, returns prometheus label of tenant. Must be called under mu.
+func (s *Service) add(tenantID uuid.UUID, metric Metric, n int64, now time.Time) string {
+	for _, p := range Periods {
+		s.counter(counterKey{tenantID: tenantID, metric: metric, period: p, bucket: p.Start(now)}).pending += n
+	}
+
+	return s.label(tenantID)
+}
+
+// Run - flush usage to DB every FlushInterval until ctx is done, then flush the rest.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			defer cancel()
+
+			s.flush(flushCtx)
+
+			return nil
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush - add pending usage to DB aggregates, pending usage is kept if DB is unavailable.
+func (s *Service) flush(ctx context.Context) {
+	now := s.now()
+
+	s.mu.Lock()
+
+	s.flushing = true
+	s.flushes++
+
+	defer func() {
+		s.mu.Lock()
+		s.flushing = false
+		s.mu.Unlock()
+	}()
+
+	rows := make([]tables.UsageCounter, 0, len(s.counters))
+	for k, c := range s.counters {
+		if c.pending == 0 {
+			if !k.period.End(k.bucket).After(now) {
+				delete(s.counters, k) // bucket is over and flushed.
+			}
+
+			continue
+		}
+
+		rows = append(rows, tables.UsageCounter{
+			TenantID:  k.tenantID,
+			Metric:    string(k.metric),
+			Period:    string(k.period),
+			Bucket:    k.bucket,
+			Value:     c.pending,
+			UpdatedAt: now.UTC(),
+		})
+		c.flushed += c.pending
+		c.pending = 0
+	}
+
+	s.mu.Unlock()
+
+	if len(rows) == 0 {
+		return
+	}
+
+	err := s.db.WithContext(tenancy.Elevate(ctx)).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tenant_id"}, {Name: "metric"}, {Name: "period"}, {Name: "bucket"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"value":      gorm.Expr("usage_counters.value + EXCLUDED.value"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).
+		Create(&rows).Error
+	if err == nil {
+		return
+	}
+
+	s.log.Error("metering flush usage", field.Int("rows", len(rows)), field.Error(err))
+
+	s.mu.Lock()
+	for _, r := range rows {
+		c := s.counter(counterKey{
+			tenantID: r.TenantID, metric: Metric(r.Metric), period: Period(r.Period), bucket: r.Bucket,
+		})
+		c.flushed -= r.Value
+		c.pending += r.Value
+	}
+	s.mu.Unlock()
+}
+
+// used - usage of bucket, it's reloaded from DB every RefreshInterval to see usage of other replicas.
+func (s *Service) used(ctx context.Context, k counterKey) (int64, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	if c, ok := s.counters[k]; ok && now.Sub(c.loadedAt) < s.cfg.RefreshInterval {
+		v := c.used()
+		s.mu.Unlock()
+
+		return v, nil
+	}
+
+	flushes, flushing := s.flushes, s.flushing
+	s.mu.Unlock()
+
+	var values []int64
+	if err := s.db.WithContext(tenancy.WithTenant(ctx, k.tenantID)).
+		Model(&tables.UsageCounter{}).
+		Where("metric = ? AND period = ? AND bucket = ?", k.metric, k.period, k.bucket).
+		Pluck("value", &values).Error; err != nil {
+		return 0, fmt.Errorf("load usage: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.counter(k)
+
+	// loaded usage may miss flushed rows, so it's applied only if no flush was in flight while it's loaded,
+	// else usage is reloaded next time.
+	if flushing || s.flushing || s.flushes != flushes {
+		return c.used(), nil
+	}
+
+	c.loaded, c.flushed, c.loadedAt = 0, 0, now
+
+	for _, v := range values {
+		c.loaded += v
+	}
+
+	return c.used(), nil
+}
+
+// used - usage of bucket known by this replica.
+func (c *counter) used() int64 {
+	return c.loaded + c.flushed + c.pending
+}
+
+// counter - counter of key, created if absent. Must be called under mu.
+func (s *Service) counter(k counterKey) *counter {
+	c, ok := s.counters[k]
+	if !ok {
+		c = &counter{}
+		s.counters[k] = c
+	}
+
+	return c
+}
+
+// label - prometheus label of tenant: first MaxTenantLabels tenants get own label. Must be called under mu.
+func (s *Service) label(tenantID uuid.UUID) string {
+	if l, ok := s.labels[tenantID]; ok {
+		return l
+	}
+
+	if len(s.labels) >= s.cfg.MaxTenantLabels {
+		return otherTenantsLabel
+	}
+
+	l := tenantID.String()
+	s.labels[tenantID] = l
+
+	return l
+}
+
+// History - usage aggregates of tenant by period in [from, to), all metrics if metric is empty.
+// Usage which isn't flushed yet isn't included.
+func (s *Service) History(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	metric Metric,
+	period Period,
+	from time.Time,
+	to time.Time,
+) ([]tables.UsageCounter, error) {
+	q := s.db.WithContext(tenancy.WithTenant(ctx, tenantID)).
+		Where("period = ? AND bucket >= ? AND bucket < ?", period, period.Start(from), to.UTC())
+
+	if metric != "" {
+		q = q.Where("metric = ?", metric)
+	}
+
+	var counters []tables.UsageCounter
+	if err := q.Order("bucket, metric").Find(&counters).Error; err != nil {
+		return nil, fmt.Errorf("usage history: %w", err)
+	}
+
+	return counters, nil
+}
@@ -0,0 +1,164 @@
+package metering
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+)
+
+type limits map[string]int64
+
+func (l limits) Settings(context.Context, uuid.UUID) (tables.TenantSettings, error) {
+	return tables.TenantSettings{Limits: l}, nil
+}
+
+func TestPeriod(t *testing.T) {
+	ts := time.Date(2024, 3, 31, 23, 42, 7, 0, time.FixedZone("CET", 3600))
+
+	assert.Equal(t, time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC), Hour.Start(ts))
+	assert.Equal(t, time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC), Hour.End(ts))
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), Day.Start(ts))
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Day.End(ts))
+}
+
+func TestService_Quotas(t *testing.T) {
+	s := New(Config{Quotas: []Quota{
+		{Metric: Requests, Period: Day, Soft: 800, Hard: 1000},
+		{Metric: Reports, Period: Hour, Hard: 10},
+	}}, nil, limits{"requests.day": 5000, "requests.hour.soft": 100, "reports.hour": 0}, nil)
+
+	quotas, err := s.Quotas(context.Background(), uuid.New(), Requests)
+	require.NoError(t, err)
+	assert.Equal(t, []Quota{
+		{Metric: Requests, Period: Hour, Soft: 100},
+		{Metric: Requests, Period: Day, Soft: 800, Hard: 5000},
+	}, quotas)
+
+	quotas, err = s.Quotas(context.Background(), uuid.New(), Reports)
+	require.NoError(t, err)
+	assert.Empty(t, quotas, "quota is disabled by tenant")
+}
+
+func TestService_Consume(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 30, 0, 0, time.UTC)
+	tenantID := uuid.New()
+
+	s := New(Config{Quotas: []Quota{{Metric: Requests, Period: Day, Soft: 2, Hard: 3}}}, nil, limits{}, nil)
+	s.now = func() time.Time { return now }
+
+	// usage is loaded recently, so DB isn't queried.
+	s.counters[counterKey{tenantID: tenantID, metric: Requests, period: Day, bucket: Day.Start(now)}] = &counter{
+		loaded: 1, loadedAt: now,
+	}
+
+	usages, err := s.Consume(context.Background(), tenantID, Requests, 1)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, int64(2), usages[0].Used)
+	assert.False(t, usages[0].SoftExceeded())
+
+	usages, err = s.Consume(context.Background(), tenantID, Requests, 1)
+	require.NoError(t, err)
+	assert.True(t, usages[0].SoftExceeded())
+
+	_, err = s.Consume(context.Background(), tenantID, Requests, 1)
+
+	var qe *QuotaError
+	require.ErrorAs(t, err, &qe)
+	assert.Equal(t, int64(3), qe.Used)
+	assert.Equal(t, Day.End(now), qe.ResetAt)
+
+	problem := apperror.From(err)
+	assert.Equal(t, "quota_exceeded", problem.Code)
+	assert.Equal(t, int64(3), problem.Extensions["limit"])
+
+	hour := s.counters[counterKey{tenantID: tenantID, metric: Requests, period: Hour, bucket: Hour.Start(now)}]
+	require.NotNil(t, hour)
+	assert.Equal(t, int64(2), hour.pending, "rejected usage isn't recorded")
+}
+
+func TestService_Consume_Concurrent(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 30, 0, 0, time.UTC)
+	tenantID := uuid.New()
+
+	s := New(Config{Quotas: []Quota{{Metric: Requests, Period: Day, Hard: 10}}}, nil, limits{}, nil)
+	s.now = func() time.Time { return now }
+	s.counters[counterKey{tenantID: tenantID, metric: Requests, period: Day, bucket: Day.Start(now)}] = &counter{
+		loadedAt: now,
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		consumed int
+	)
+
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := s.Consume(context.Background(), tenantID, Requests, 1); err == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, consumed, "concurrent requests can't exceed hard limit together")
+}
+
+func TestService_Used_Flushing(t *testing.T) {
+	db := dbtest.DryRun(t)
+
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:usage", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]int64); ok {
+			*dest = []int64{5} // flushed rows are already in DB.
+		}
+	}))
+
+	now := time.Date(2024, 3, 31, 12, 30, 0, 0, time.UTC)
+	k := counterKey{tenantID: uuid.New(), metric: Requests, period: Day, bucket: Day.Start(now)}
+
+	s := New(Config{}, &database.DB{DB: db}, limits{}, nil)
+	s.now = func() time.Time { return now }
+	s.counters[k] = &counter{loaded: 2, flushed: 3, loadedAt: now.Add(-time.Hour)}
+	s.flushing = true
+
+	used, err := s.used(context.Background(), k)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), used)
+	assert.Equal(t, int64(3), s.counters[k].flushed, "flushed usage is kept while flush is in flight")
+
+	s.flushing = false
+
+	used, err = s.used(context.Background(), k)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), used)
+	assert.Equal(t, int64(0), s.counters[k].flushed)
+	assert.Equal(t, int64(5), s.counters[k].loaded)
+}
+
+func TestService_Label(t *testing.T) {
+	s := New(Config{MaxTenantLabels: 1}, nil, limits{}, nil)
+	first, second := uuid.New(), uuid.New()
+
+	assert.Equal(t, first.String(), s.label(first))
+	assert.Equal(t, otherTenantsLabel, s.label(second))
+	assert.Equal(t, first.String(), s.label(first))
+}
@@ -0,0 +1,195 @@
+package metering
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imperiuse/go-app-skeleton/internal/apperror"
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+)
+
+// softLimitSuffix - suffix of soft limit name in tenant settings, @see Quota.
+const softLimitSuffix = ".soft"
+
+// Metrics - all metered resources.
+var Metrics = [...]Metric{Requests, Reports, StorageBytes}
+
+type (
+	// Quota - limits of metric usage per period, zero limit means no limit. Soft limit only warns, hard limit rejects.
+	// Tenant settings override default quotas by limits `<metric>.<period>` (hard) and `<metric>.<period>.soft`,
+	// e.g. `requests.day` and `requests.day.soft`.
+	Quota struct {
+		Metric Metric
+		Period Period
+		Soft   int64
+		Hard   int64
+	}
+
+	// Usage - usage of quota in current period.
+	Usage struct {
+		Quota
+		Used    int64
+		ResetAt time.Time // start of next period.
+	}
+
+	// QuotaError - hard limit of quota is exceeded, it unwraps to `quota_exceeded` problem.
+	QuotaError struct {
+		Usage
+	}
+)
+
+// SoftExceeded - soft limit is exceeded.
+func (u Usage) SoftExceeded() bool {
+	return u.Soft > 0 && u.Used > u.Soft
+}
+
+// Error - implements error.
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota %s/%s exceeded: used %d of %d", e.Metric, e.Period, e.Used, e.Hard)
+}
+
+// Unwrap - problem details of error, @see apperror.From.
+func (e *QuotaError) Unwrap() error {
+	return apperror.ErrQuotaExceeded.
+		WithDetailf("%s quota per %s is exceeded, it's reset at %s", e.Metric, e.Period, e.ResetAt.Format(time.RFC3339)).
+		With("metric", string(e.Metric)).
+		With("period", string(e.Period)).
+		With("limit", e.Hard).
+		With("used", e.Used)
+}
+
+// Check - check that n units of metric can be used by tenant. Returns usage of quotas of metric (including n)
+// or *QuotaError if hard limit would be exceeded. Units aren't recorded, @see Consume.
+func (s *Service) Check(ctx context.Context, tenantID uuid.UUID, metric Metric, n int64) ([]Usage, error) {
+	return s.check(ctx, tenantID, metric, n, false)
+}
+
+// Consume - Check and Record n units of metric atomically, so concurrent requests can't exceed hard limit together.
+func (s *Service) Consume(ctx context.Context, tenantID uuid.UUID, metric Metric, n int64) ([]Usage, error) {
+	return s.check(ctx, tenantID, metric, n, true)
+}
+
+// check - usage is refreshed from DB out of mu, then checked (and reserved) under mu.
+func (s *Service) check(ctx context.Context, tenantID uuid.UUID, metric Metric, n int64, reserve bool) (
+	[]Usage,
+	error,
+) {
+	quotas, err := s.Quotas(ctx, tenantID, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	for _, q := range quotas {
+		if _, err = s.usage(ctx, tenantID, q, now); err != nil {
+			return nil, err
+		}
+	}
+
+	usages := make([]Usage, 0, len(quotas))
+
+	s.mu.Lock()
+
+	for _, q := range quotas {
+		c := s.counter(counterKey{tenantID: tenantID, metric: q.Metric, period: q.Period, bucket: q.Period.Start(now)})
+		u := Usage{Quota: q, Used: c.used(), ResetAt: q.Period.End(now)}
+
+		if q.Hard > 0 && u.Used+n > q.Hard {
+			s.mu.Unlock()
+			metrics.QuotaExceededInc(string(metric), string(q.Period))
+
+			return nil, &QuotaError{Usage: u}
+		}
+
+		u.Used += n
+		usages = append(usages, u)
+	}
+
+	var label string
+	if reserve {
+		label = s.add(tenantID, metric, n, now)
+	}
+
+	s.mu.Unlock()
+
+	if reserve && n > 0 {
+		metrics.UsageAdd(label, string(metric), float64(n))
+	}
+
+	return usages, nil
+}
+
+// Quotas - quotas of metric for tenant: default quotas overridden by tenant settings.
+func (s *Service) Quotas(ctx context.Context, tenantID uuid.UUID, metric Metric) ([]Quota, error) {
+	settings, err := s.limits.Settings(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("tenant limits: %w", err)
+	}
+
+	var quotas []Quota
+
+	for _, p := range Periods {
+		q := Quota{Metric: metric, Period: p}
+
+		for _, d := range s.cfg.Quotas {
+			if d.Metric == metric && d.Period == p {
+				q = d
+			}
+		}
+
+		name := string(metric) + "." + string(p)
+		if v, ok := settings.Limits[name]; ok {
+			q.Hard = v
+		}
+
+		if v, ok := settings.Limits[name+softLimitSuffix]; ok {
+			q.Soft = v
+		}
+
+		if q.Soft > 0 || q.Hard > 0 {
+			quotas = append(quotas, q)
+		}
+	}
+
+	return quotas, nil
+}
+
+// CurrentUsage - usage of all quotas of tenant in current periods.
+func (s *Service) CurrentUsage(ctx context.Context, tenantID uuid.UUID) ([]Usage, error) {
+	now := s.now()
+
+	var usages []Usage
+
+	for _, m := range Metrics {
+		quotas, err := s.Quotas(ctx, tenantID, m)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, q := range quotas {
+			u, err := s.usage(ctx, tenantID, q, now)
+			if err != nil {
+				return nil, err
+			}
+
+			usages = append(usages, u)
+		}
+	}
+
+	return usages, nil
+}
+
+func (s *Service) usage(ctx context.Context, tenantID uuid.UUID, q Quota, now time.Time) (Usage, error) {
+	k := counterKey{tenantID: tenantID, metric: q.Metric, period: q.Period, bucket: q.Period.Start(now)}
+
+	used, err := s.used(ctx, k)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{Quota: q, Used: used, ResetAt: q.Period.End(now)}, nil
+}
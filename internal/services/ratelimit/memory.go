@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// cleanupInterval - how often full (idle) buckets are removed from memory.
+const cleanupInterval = time.Minute
+
+type (
+	// Memory - in-memory limiter, limits are per replica.
+	Memory struct {
+		mu          sync.Mutex
+		buckets     map[string]*bucket
+		lastCleanup time.Time
+
+		now func() time.Time
+	}
+
+	bucket struct {
+		tokens    float64
+		updatedAt time.Time
+		limit     Limit
+	}
+)
+
+// NewMemory - constructor of in-memory limiter.
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take - take one token from bucket.
+func (m *Memory) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	if limit.IsZero() {
+		return Result{Allowed: true}, nil
+	}
+
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cleanup(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return limit.result(false, b.tokens), nil
+	}
+
+	b.tokens--
+
+	return limit.result(true, b.tokens), nil
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.burst()), b.tokens+elapsed*b.limit.ratePerSecond())
+		b.updatedAt = now
+	}
+}
+
+// cleanup - remove buckets, which are full, it's the same as absent bucket. Must be called under lock.
+func (m *Memory) cleanup(now time.Time) {
+	if now.Sub(m.lastCleanup) < cleanupInterval {
+		return
+	}
+
+	m.lastCleanup = now
+
+	for key, b := range m.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.burst()) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 1, Per: time.Second, Burst: 2}
+
+	res, err := m.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, res)
+
+	res, _ = m.Take(ctx, "a", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, _ = m.Take(ctx, "a", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	res, _ = m.Take(ctx, "b", limit)
+	assert.True(t, res.Allowed, "other key has own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = m.Take(ctx, "a", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = m.Take(ctx, "a", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(time.Hour)
+	res, _ = m.Take(ctx, "a", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining, "bucket never holds more than burst")
+	assert.Len(t, m.buckets, 1, "idle full buckets are cleaned up")
+}
+
+func TestMemory_TakeZeroLimit(t *testing.T) {
+	res, err := NewMemory().Take(context.Background(), "a", Limit{})
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
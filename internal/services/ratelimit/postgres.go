@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+)
+
+const (
+	purgeInterval = 10 * time.Minute
+	purgeIdleTime = time.Hour
+)
+
+// takeTokenSQL - atomic refill and take of token in one statement, so replicas share the same bucket.
+const takeTokenSQL = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (@key, @burst - 1, TRUE, NOW())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE
+        WHEN LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at)) * @rate) >= 1
+        THEN LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at)) * @rate) - 1
+        ELSE LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at)) * @rate)
+    END,
+    allowed = LEAST(@burst, b.tokens + EXTRACT(EPOCH FROM (NOW() - b.updated_at)) * @rate) >= 1,
+    updated_at = NOW()
+RETURNING tokens, allowed`
+
+// purgeSQL - buckets which are full again are useless.
+const purgeSQL = `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => ?)`
+
+// Postgres - postgres based limiter, limits are shared between all replicas.
+type Postgres struct {
+	db  *database.DB
+	log *logger.Logger
+}
+
+// NewPostgres - constructor of postgres limiter.
+func NewPostgres(db *database.DB, log *logger.Logger) *Postgres {
+	return &Postgres{db: db, log: log}
+}
+
+// Take - take one token from bucket.
+func (p *Postgres) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.IsZero() {
+		return Result{Allowed: true}, nil
+	}
+
+	var row struct {
+		Tokens  float64
+		Allowed bool
+	}
+
+	// in transaction: rows of tenant context are read only in it, @see tenancy.Config.
+	if err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Raw(takeTokenSQL, map[string]any{
+			"key":   key,
+			"burst": float64(limit.burst()),
+			"rate":  limit.ratePerSecond(),
+		}).Scan(&row).Error
+	}); err != nil {
+		return Result{}, fmt.Errorf("take token: %w", err)
+	}
+
+	return limit.result(row.Allowed, row.Tokens), nil
+}
+
+// PurgeIdle - delete buckets which were not used longer than idle time.
+func (p *Postgres) PurgeIdle(ctx context.Context, idle time.Duration) (int64, error) {
+	res := p.db.WithContext(ctx).Exec(purgeSQL, idle.Seconds())
+	if res.Error != nil {
+		return 0, fmt.Errorf("purge rate limit buckets: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// RunPurger - periodically purge idle buckets until ctx is done.
+func (p *Postgres) RunPurger(ctx context.Context) error {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := p.PurgeIdle(ctx, purgeIdleTime); err != nil {
+				p.log.Error("rate limit buckets purger", field.Error(err))
+			}
+		}
+	}
+}
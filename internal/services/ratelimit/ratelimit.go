@@ -0,0 +1,78 @@
+// Package ratelimit - token bucket rate limiter with in-memory and postgres (cross replicas) backends.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+type (
+	// Limit - token bucket params: bucket refills with Requests tokens per Per, and holds at most Burst tokens.
+	Limit struct {
+		Requests int
+		Per      time.Duration
+		Burst    int
+	}
+
+	// Result - result of taking token from bucket.
+	Result struct {
+		Allowed    bool
+		Limit      int           // bucket size (burst).
+		Remaining  int           // tokens left after this request.
+		Reset      time.Duration // time until bucket is full again.
+		RetryAfter time.Duration // time until next token is available, zero if allowed.
+	}
+
+	// Limiter - rate limiter backend.
+	Limiter interface {
+		// Take - take one token from bucket with given key.
+		Take(ctx context.Context, key string, limit Limit) (Result, error)
+	}
+)
+
+// IsZero - limit is not configured, so requests are not limited.
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// ratePerSecond - tokens refilled per second.
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// burst - bucket size, at least one token.
+func (l Limit) burst() int {
+	if l.Burst <= 0 {
+		return 1
+	}
+
+	return l.Burst
+}
+
+// result - build result for bucket with `tokens` left after (possibly denied) take.
+func (l Limit) result(allowed bool, tokens float64) Result {
+	rate := l.ratePerSecond()
+	burst := l.burst()
+
+	r := Result{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsToDuration((float64(burst) - tokens) / rate),
+	}
+
+	if !allowed {
+		r.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+
+	return r
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,100 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Permission - one bit of `roles.rights SMALLINT` bitmask.
+// NB! SMALLINT is signed, so only 15 bits are available. Never reorder existing permissions, append only.
+type Permission uint16
+
+const (
+	ReadReports Permission = 1 << iota
+	WriteReports
+	ManageUsers
+	ManageRoles
+	ManageAPIKeys
+	ManageTenants
+	ViewUsage
+)
+
+// Rights - set of permissions (bitwise OR of Permission).
+type Rights = Permission
+
+// UserOnly - permissions on global (not tenant) objects: they are granted only to users, never to api keys,
+// which act only on own tenant.
+const UserOnly = ManageRoles | ManageTenants
+
+var ErrUnknownPermission = errors.New("unknown permission")
+
+var permissionNames = map[Permission]string{
+	ReadReports:   "reports:read",
+	WriteReports:  "reports:write",
+	ManageUsers:   "users:manage",
+	ManageRoles:   "roles:manage",
+	ManageAPIKeys: "api_keys:manage",
+	ManageTenants: "tenants:manage",
+	ViewUsage:     "usage:view",
+}
+
+// Has - check that all permissions of p are in r.
+func (r Rights) Has(p Permission) bool {
+	return p != 0 && r&p == p
+}
+
+// String - comma separated names, e.g. "reports:read,roles:manage".
+func (r Rights) String() string {
+	return strings.Join(r.Names(), ",")
+}
+
+// Names - sorted names of permissions in r.
+func (r Rights) Names() []string {
+	names := make([]string, 0, bits.OnesCount16(uint16(r)))
+	for p, name := range permissionNames {
+		if r.Has(p) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// AllPermissions - names of all known permissions.
+func AllPermissions() []string {
+	var all Rights
+	for p := range permissionNames {
+		all |= p
+	}
+
+	return all.Names()
+}
+
+// ParseRights - convert permission names to rights bitmask.
+func ParseRights(names []string) (Rights, error) {
+	var r Rights
+	for _, name := range names {
+		p, ok := permissionByName(name)
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+		r |= p
+	}
+
+	return r, nil
+}
+
+func permissionByName(name string) (Permission, bool) {
+	for p, n := range permissionNames {
+		if n == name {
+			return p, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,36 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRights(t *testing.T) {
+	r := ReadReports | ManageRoles
+
+	assert.True(t, r.Has(ReadReports))
+	assert.True(t, r.Has(ManageRoles))
+	assert.True(t, r.Has(ReadReports|ManageRoles))
+	assert.False(t, r.Has(WriteReports))
+	assert.False(t, r.Has(ReadReports|WriteReports))
+	assert.False(t, r.Has(0))
+
+	assert.Equal(t, []string{"reports:read", "roles:manage"}, r.Names())
+	assert.Equal(t, "reports:read,roles:manage", r.String())
+}
+
+func TestParseRights(t *testing.T) {
+	r, err := ParseRights([]string{"roles:manage", "reports:read"})
+	require.NoError(t, err)
+	assert.Equal(t, ReadReports|ManageRoles, r)
+
+	r, err = ParseRights(AllPermissions())
+	require.NoError(t, err)
+	assert.Len(t, r.Names(), len(permissionNames))
+	assert.Positive(t, int16(r), "rights must fit in signed SMALLINT and satisfy CHECK (rights > 0)")
+
+	_, err = ParseRights([]string{"reports:read", "unknown"})
+	assert.ErrorIs(t, err, ErrUnknownPermission)
+}
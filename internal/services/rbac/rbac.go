@@ -0,0 +1,289 @@
+// Package rbac - role based access control, based on `roles.rights` bitmasks and `users_roles`.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+)
+
+const defaultCacheTTL = time.Minute
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleExists   = errors.New("role with the name already exists")
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmptyRights  = errors.New("role must have at least one permission")
+)
+
+type (
+	// Config - rbac service config.
+	Config struct {
+		CacheTTL time.Duration // how long user's rights are cached.
+	}
+
+	// Service - rbac service.
+	Service struct {
+		cfg Config
+		db  *database.DB
+
+		mu       sync.RWMutex
+		cache    map[int]cachedRights // user_id -> rights.
+		version  uint64               // incremented by invalidation, rights loaded before it aren't cached.
+		prunedAt time.Time            // last time expired entries were evicted.
+
+		now func() time.Time
+	}
+
+	cachedRights struct {
+		rights    Rights
+		expiredAt time.Time
+	}
+)
+
+// New - constructor of rbac Service.
+func New(cfg Config, db *database.DB) *Service {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+
+	return &Service{cfg: cfg, db: db, cache: make(map[int]cachedRights), now: time.Now}
+}
+
+// UserRights - union of rights of all user's roles (cached).
+func (s *Service) UserRights(ctx context.Context, userID int) (Rights, error) {
+	r, version, ok := s.fromCache(userID)
+	if ok {
+		return r, nil
+	}
+
+	var rights []int16
+	if err := s.db.WithContext(ctx).
+		Model(&tables.Role{}).
+		Joins("JOIN users_roles ON users_roles.role_id = roles.id").
+		Where("users_roles.user_id = ?", userID).
+		Pluck("roles.rights", &rights).Error; err != nil {
+		return 0, fmt.Errorf("load user rights: %w", err)
+	}
+
+	for _, v := range rights {
+		r |= Rights(v)
+	}
+
+	s.store(userID, r, version)
+
+	return r, nil
+}
+
+// HasPermission - check that user has permission p.
+func (s *Service) HasPermission(ctx context.Context, userID int, p Permission) (bool, error) {
+	r, err := s.UserRights(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return r.Has(p), nil
+}
+
+// ListRoles - list all roles.
+func (s *Service) ListRoles(ctx context.Context) ([]tables.Role, error) {
+	var roles []tables.Role
+	if err := s.db.WithContext(ctx).Order("id").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// CreateRole - create new role.
+func (s *Service) CreateRole(ctx context.Context, name string, rights Rights) (*tables.Role, error) {
+	if rights == 0 {
+		return nil, ErrEmptyRights
+	}
+
+	now := s.now().UTC()
+	role := &tables.Role{CreatedAt: now, UpdatedAt: now, Name: name, Rights: int16(rights)}
+	if err := s.db.WithContext(ctx).Create(role).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrRoleExists
+		}
+
+		return nil, fmt.Errorf("create role: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateRole - update name and rights of role.
+func (s *Service) UpdateRole(ctx context.Context, roleID int, name string, rights Rights) (*tables.Role, error) {
+	if rights == 0 {
+		return nil, ErrEmptyRights
+	}
+
+	role := &tables.Role{ID: roleID}
+	res := s.db.WithContext(ctx).Model(role).Updates(map[string]any{
+		"name":       name,
+		"rights":     int16(rights),
+		"updated_at": s.now().UTC(),
+	})
+	if res.Error != nil {
+		if database.IsUniqueViolation(res.Error) {
+			return nil, ErrRoleExists
+		}
+
+		return nil, fmt.Errorf("update role: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return nil, ErrRoleNotFound
+	}
+
+	s.InvalidateAll()
+
+	return s.getRole(ctx, roleID)
+}
+
+// DeleteRole - delete role (assignments are deleted by cascade).
+func (s *Service) DeleteRole(ctx context.Context, roleID int) error {
+	res := s.db.WithContext(ctx).Delete(&tables.Role{}, roleID)
+	if res.Error != nil {
+		return fmt.Errorf("delete role: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrRoleNotFound
+	}
+
+	s.InvalidateAll()
+
+	return nil
+}
+
+// UserRoles - list roles of user.
+func (s *Service) UserRoles(ctx context.Context, userID int) ([]tables.Role, error) {
+	var roles []tables.Role
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN users_roles ON users_roles.role_id = roles.id").
+		Where("users_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("list user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// AssignRole - assign role to user (idempotent).
+func (s *Service) AssignRole(ctx context.Context, userID int, roleID int) error {
+	if _, err := s.getRole(ctx, roleID); err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	if err := s.db.WithContext(ctx).
+		Where(tables.UserRole{UserID: userID, RoleID: roleID}).
+		Attrs(tables.UserRole{CreatedAt: now, UpdatedAt: now}).
+		FirstOrCreate(&tables.UserRole{}).Error; err != nil {
+		switch {
+		case database.IsForeignKeyViolation(err): // role is checked above, unless it's deleted concurrently.
+			return ErrUserNotFound
+		case database.IsUniqueViolation(err): // concurrent assignment.
+			return nil
+		default:
+			return fmt.Errorf("assign role: %w", err)
+		}
+	}
+
+	s.Invalidate(userID)
+
+	return nil
+}
+
+// UnassignRole - remove role from user.
+func (s *Service) UnassignRole(ctx context.Context, userID int, roleID int) error {
+	res := s.db.WithContext(ctx).Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&tables.UserRole{})
+	if res.Error != nil {
+		return fmt.Errorf("unassign role: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrRoleNotFound
+	}
+
+	s.Invalidate(userID)
+
+	return nil
+}
+
+// Invalidate - drop cached rights of user.
+func (s *Service) Invalidate(userID int) {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.version++
+	s.mu.Unlock()
+}
+
+// InvalidateAll - drop all cached rights (role itself was changed).
+func (s *Service) InvalidateAll() {
+	s.mu.Lock()
+	s.cache = make(map[int]cachedRights)
+	s.version++
+	s.mu.Unlock()
+}
+
+// fromCache - cached rights of user, else cache version which must be passed to store.
+func (s *Service) fromCache(userID int) (Rights, uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.cache[userID]
+	if !ok || s.now().After(c.expiredAt) {
+		return 0, s.version, false
+	}
+
+	return c.rights, s.version, true
+}
+
+// store - cache rights loaded at cache version, unless cache was invalidated since then (rights may be stale).
+// Expired entries are evicted once per CacheTTL.
+func (s *Service) store(userID int, r Rights, version uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version != s.version {
+		return
+	}
+
+	now := s.now()
+	if now.Sub(s.prunedAt) >= s.cfg.CacheTTL {
+		for id, c := range s.cache {
+			if now.After(c.expiredAt) {
+				delete(s.cache, id)
+			}
+		}
+
+		s.prunedAt = now
+	}
+
+	s.cache[userID] = cachedRights{rights: r, expiredAt: now.Add(s.cfg.CacheTTL)}
+}
+
+func (s *Service) getRole(ctx context.Context, roleID int) (*tables.Role, error) {
+	role := &tables.Role{}
+	if err := s.db.WithContext(ctx).Take(role, roleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+
+		return nil, fmt.Errorf("get role: %w", err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,97 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+)
+
+// newTestService - service over dry run db, rights of users are loaded by load.
+func newTestService(t *testing.T, load func(userID int) []int16) *Service {
+	db := dbtest.DryRun(t)
+
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:rights", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]int16); ok {
+			*dest = load(tx.Statement.Vars[0].(int)) //nolint:forcetypeassert // user_id is int.
+		}
+	}))
+
+	return New(Config{CacheTTL: time.Minute}, &database.DB{DB: db})
+}
+
+func TestService_UserRights_Cache(t *testing.T) {
+	loads := 0
+	rights := map[int]Rights{1: ReadReports, 2: ManageRoles}
+
+	var s *Service
+
+	s = newTestService(t, func(userID int) []int16 {
+		loads++
+
+		if loads == 1 {
+			s.Invalidate(userID) // concurrent role change while rights are loaded.
+		}
+
+		return []int16{int16(rights[userID])}
+	})
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	r, err := s.UserRights(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, ReadReports, r)
+	assert.Empty(t, s.cache, "rights loaded before invalidation aren't cached")
+
+	_, err = s.UserRights(ctx, 1)
+	require.NoError(t, err)
+	_, err = s.UserRights(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads, "rights are cached")
+
+	now = now.Add(2 * time.Minute)
+
+	r, err = s.UserRights(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, ManageRoles, r)
+	assert.Len(t, s.cache, 1, "expired entries are evicted")
+	assert.Contains(t, s.cache, 2)
+}
+
+// pgError - postgres error with code, @see database.IsUniqueViolation.
+type pgError string
+
+func (e pgError) Error() string { return "pg error " + string(e) }
+
+func (e pgError) SQLState() string { return string(e) }
+
+func TestService_Violations(t *testing.T) {
+	s := newTestService(t, func(int) []int16 { return nil })
+
+	var code pgError
+
+	require.NoError(t, s.db.Callback().Create().After("gorm:create").Register("test:violation", func(tx *gorm.DB) {
+		_ = tx.AddError(code)
+	}))
+
+	ctx := context.Background()
+
+	code = "23505"
+	_, err := s.CreateRole(ctx, "admin", ManageRoles)
+	require.ErrorIs(t, err, ErrRoleExists)
+
+	code = "23503"
+	require.ErrorIs(t, s.AssignRole(ctx, 1, 2), ErrUserNotFound)
+
+	code = "23505"
+	require.NoError(t, s.AssignRole(ctx, 1, 2), "role is assigned concurrently")
+}
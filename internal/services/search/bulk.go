@@ -0,0 +1,116 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/searchdoc"
+)
+
+// Op - bulk operation.
+type Op string
+
+const (
+	OpIndex  Op = "index"
+	OpDelete Op = "delete"
+)
+
+type (
+	// Document - document of index, @see searchdoc.Document.
+	Document = searchdoc.Document
+
+	// Indexable - model which is indexed, @see Plugin.
+	Indexable = searchdoc.Indexable
+
+	// Action - bulk action: index (create or replace) or delete document.
+	Action struct {
+		Op Op
+		Document
+	}
+
+	// BulkItemError - failed action of bulk request.
+	BulkItemError struct {
+		Item   int // position in actions.
+		Action Action
+		Status int
+		Type   string
+		Reason string
+	}
+
+	bulkMeta struct {
+		Index   string `json:"_index"`
+		ID      string `json:"_id"`
+		Routing string `json:"routing,omitempty"`
+	}
+)
+
+// Bulk - execute actions by one _bulk request. Returned failed items are not retried by cluster,
+// error is returned only if whole request is failed.
+func (c *Client) Bulk(ctx context.Context, actions []Action) ([]BulkItemError, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf) // Encode writes `\n` after each value, as NDJSON requires.
+
+	for _, a := range actions {
+		meta := map[Op]bulkMeta{a.Op: {Index: c.IndexName(a.Index), ID: a.ID, Routing: a.Routing}}
+		if err := enc.Encode(meta); err != nil {
+			return nil, fmt.Errorf("search: bulk encode meta: %w", err)
+		}
+
+		if a.Op == OpDelete {
+			continue
+		}
+
+		if err := enc.Encode(a.Body); err != nil {
+			return nil, fmt.Errorf("search: bulk encode document %s/%s: %w", a.Index, a.ID, err)
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[Op]struct {
+			Status int `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/_bulk", buf.Bytes(), ndjsonContentType, &resp); err != nil {
+		return nil, err
+	}
+
+	if !resp.Errors {
+		return nil, nil
+	}
+
+	var failed []BulkItemError
+
+	for i, item := range resp.Items {
+		for _, r := range item {
+			// delete of absent document is not an error for sync purposes.
+			if r.Error == nil || i >= len(actions) || (actions[i].Op == OpDelete && r.Status == http.StatusNotFound) {
+				continue
+			}
+
+			failed = append(failed, BulkItemError{
+				Item: i, Action: actions[i], Status: r.Status, Type: r.Error.Type, Reason: r.Error.Reason,
+			})
+		}
+	}
+
+	return failed, nil
+}
+
+// Retryable - temporary error of item (overload of cluster).
+func (e BulkItemError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError
+}
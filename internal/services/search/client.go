@@ -0,0 +1,307 @@
+// Package search - indexing of models into Elasticsearch/OpenSearch compatible cluster and search in it.
+//
+// Only small subset of REST API is used (_bulk, _search, index creation), so plain http client is enough.
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/requestid"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	maxErrorBodyLen = 4096
+
+	ndjsonContentType = "application/x-ndjson"
+	jsonContentType   = "application/json"
+)
+
+var ErrNoAddresses = errors.New("search: no cluster addresses")
+
+type (
+	// Config - settings of cluster client.
+	Config struct {
+		Addresses   []string // e.g. http://localhost:9200, requests are balanced round-robin.
+		Username    string
+		Password    string
+		IndexPrefix string // prefix of all indexes of service, e.g. `reports-service-`.
+		Timeout     time.Duration
+		Logger      RoundTripLogger // optional, e.g. logger.LoggerForES.
+	}
+
+	// RoundTripLogger - logger of requests to cluster (interface of elastic transport logger).
+	RoundTripLogger interface {
+		LogRoundTrip(req *http.Request, res *http.Response, err error, start time.Time, dur time.Duration) error
+	}
+
+	loggingTransport struct {
+		next http.RoundTripper
+		log  RoundTripLogger
+	}
+
+	// Client - client of Elasticsearch/OpenSearch compatible cluster.
+	Client struct {
+		cfg  Config
+		http *http.Client
+		next atomic.Uint32
+	}
+
+	// ResponseError - non 2xx response of cluster.
+	ResponseError struct {
+		Status int
+		Type   string
+		Reason string
+	}
+
+	// Query - search query, all conditions are joined by AND.
+	Query struct {
+		Index    string // without prefix.
+		TenantID string // routing and `tenant_id` term filter.
+		Text     string // simple_query_string syntax.
+		From     time.Time
+		To       time.Time // exclusive.
+		Sort     sorting.Sort
+		Offset   int
+		Limit    int
+	}
+
+	// Result - search result.
+	Result struct {
+		Total int
+		Hits  []Hit
+	}
+
+	// Hit - found document.
+	Hit struct {
+		Index  string          `json:"_index"`
+		ID     string          `json:"_id"`
+		Score  *float64        `json:"_score"`
+		Source json.RawMessage `json:"_source"`
+	}
+)
+
+// NewClient - constructor of Client. Outbound requests carry X-Request-Id of context.
+func NewClient(cfg Config) (*Client, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, ErrNoAddresses
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	for i, addr := range cfg.Addresses {
+		cfg.Addresses[i] = strings.TrimRight(addr, "/")
+	}
+
+	var transport http.RoundTripper = requestid.NewTransport(nil)
+	if cfg.Logger != nil {
+		transport = &loggingTransport{next: transport, log: cfg.Logger}
+	}
+
+	return &Client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+	}, nil
+}
+
+// IndexName - full name of index (with prefix).
+func (c *Client) IndexName(index string) string {
+	return c.cfg.IndexPrefix + index
+}
+
+// Ping - check that cluster is available.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/", nil, "", nil)
+}
+
+// EnsureIndex - create index with given mappings (body of create index API) if it doesn't exist.
+func (c *Client) EnsureIndex(ctx context.Context, index string, body any) error {
+	err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(c.IndexName(index)), body, "", nil)
+
+	var respErr *ResponseError
+	if errors.As(err, &respErr) && respErr.Type == "resource_already_exists_exception" {
+		return nil
+	}
+
+	return err
+}
+
+// Search - search documents.
+func (c *Client) Search(ctx context.Context, q Query) (Result, error) {
+	filters := []any{}
+	if q.TenantID != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"tenant_id": q.TenantID}})
+	}
+
+	if !q.From.IsZero() || !q.To.IsZero() {
+		r := map[string]any{}
+		if !q.From.IsZero() {
+			r["gte"] = q.From.Format(time.RFC3339Nano)
+		}
+
+		if !q.To.IsZero() {
+			r["lt"] = q.To.Format(time.RFC3339Nano)
+		}
+
+		filters = append(filters, map[string]any{"range": map[string]any{"created_at": r}})
+	}
+
+	boolQuery := map[string]any{"filter": filters}
+	if q.Text != "" {
+		boolQuery["must"] = map[string]any{
+			"simple_query_string": map[string]any{"query": q.Text, "default_operator": "and"},
+		}
+	}
+
+	sort := make([]any, 0, len(q.Sort))
+	for _, o := range q.Sort {
+		order := "asc"
+		if o.Desc {
+			order = "desc"
+		}
+
+		sort = append(sort, map[string]any{o.Column: map[string]any{"order": order}})
+	}
+
+	body := map[string]any{
+		"from":             q.Offset,
+		"size":             q.Limit,
+		"track_total_hits": true,
+		"query":            map[string]any{"bool": boolQuery},
+		"sort":             sort,
+	}
+
+	path := "/" + url.PathEscape(c.IndexName(q.Index)) + "/_search"
+	if q.TenantID != "" {
+		path += "?routing=" + url.QueryEscape(q.TenantID)
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []Hit `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, path, body, "", &resp); err != nil {
+		return Result{}, err
+	}
+
+	for i := range resp.Hits.Hits {
+		resp.Hits.Hits[i].Index = strings.TrimPrefix(resp.Hits.Hits[i].Index, c.cfg.IndexPrefix)
+	}
+
+	return Result{Total: resp.Hits.Total.Value, Hits: resp.Hits.Hits}, nil
+}
+
+// do - send request (body is marshaled to json if it's not []byte) and decode json response into result.
+func (c *Client) do(ctx context.Context, method, path string, body any, contentType string, result any) error {
+	var reader io.Reader
+
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("search: marshal request: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	addr := c.cfg.Addresses[int(c.next.Add(1)-1)%len(c.cfg.Addresses)]
+
+	req, err := http.NewRequestWithContext(ctx, method, addr+path, reader)
+	if err != nil {
+		return fmt.Errorf("search: new request: %w", err)
+	}
+
+	if contentType == "" {
+		contentType = jsonContentType
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", jsonContentType)
+
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("search: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return newResponseError(resp)
+	}
+
+	if result == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("search: decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+func newResponseError(resp *http.Response) *ResponseError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+
+	var body struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+
+	e := &ResponseError{Status: resp.StatusCode}
+	if json.Unmarshal(data, &body) == nil {
+		e.Type, e.Reason = body.Error.Type, body.Error.Reason
+	} else {
+		e.Reason = string(data)
+	}
+
+	return e
+}
+
+// Error - implements error.
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("search: status %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Retryable - temporary error (overload or unavailable cluster).
+func (e *ResponseError) Retryable() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError
+}
+
+// RoundTrip - implements http.RoundTripper.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	_ = t.log.LogRoundTrip(req, res, err, start, time.Since(start))
+
+	return res, err
+}
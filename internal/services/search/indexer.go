@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+	"github.com/imperiuse/go-app-skeleton/internal/metrics"
+)
+
+const (
+	defaultBulkSize      = 500
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 10000
+	maxAttempts          = 3
+	retryBackoff         = 500 * time.Millisecond
+	finalFlushTimeout    = 5 * time.Second
+)
+
+type (
+	// IndexerConfig - settings of Indexer.
+	IndexerConfig struct {
+		BulkSize      int           // max actions in one _bulk request.
+		FlushInterval time.Duration // max delay of action.
+		QueueSize     int           // actions over it are dropped (and counted in metrics), writes are never blocked.
+	}
+
+	// Indexer - asynchronous batched sync of documents into cluster (eventual consistency).
+	Indexer struct {
+		client *Client
+		cfg    IndexerConfig
+		log    *logger.Logger
+
+		queue chan Action
+	}
+)
+
+// NewIndexer - constructor of Indexer, call Run to start sending.
+func NewIndexer(client *Client, cfg IndexerConfig, log *logger.Logger) *Indexer {
+	if cfg.BulkSize <= 0 {
+		cfg.BulkSize = defaultBulkSize
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	return &Indexer{client: client, cfg: cfg, log: log, queue: make(chan Action, cfg.QueueSize)}
+}
+
+// Enqueue - add action to queue, never blocks: action is dropped if queue is full.
+func (i *Indexer) Enqueue(a Action) {
+	select {
+	case i.queue <- a:
+	default:
+		metrics.SearchIndexedInc(a.Index, string(a.Op), metrics.Dropped)
+		i.log.Warn("search indexer queue is full, action dropped",
+			field.String("index", a.Index), field.String("doc_id", a.ID))
+	}
+}
+
+// Run - send queued actions by batches until ctx is done, then flush the rest.
+func (i *Indexer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(i.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Action, 0, i.cfg.BulkSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			for len(i.queue) > 0 {
+				batch = append(batch, <-i.queue)
+			}
+
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			for len(batch) > 0 {
+				n := min(len(batch), i.cfg.BulkSize)
+				i.flush(flushCtx, batch[:n])
+				batch = batch[n:]
+			}
+			cancel()
+
+			return nil
+		case a := <-i.queue:
+			if batch = append(batch, a); len(batch) >= i.cfg.BulkSize {
+				i.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				i.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush - send batch, retry whole batch on temporary errors and only failed items on temporary item errors.
+func (i *Indexer) flush(ctx context.Context, batch []Action) {
+	pending := batch
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		failed, err := i.client.Bulk(ctx, pending)
+		if err != nil {
+			var respErr *ResponseError
+			if (errors.As(err, &respErr) && !respErr.Retryable()) || attempt == maxAttempts ||
+				!sleep(ctx, retryBackoff*time.Duration(attempt)) {
+				i.log.Error("search bulk", field.Int("actions", len(pending)), field.Error(err))
+				count(pending, metrics.Failed)
+
+				return
+			}
+
+			i.log.Warn("search bulk, retry", field.Int("attempt", attempt), field.Error(err))
+
+			continue
+		}
+
+		failedItems := make(map[int]struct{}, len(failed))
+
+		var retry []Action
+
+		for _, f := range failed {
+			failedItems[f.Item] = struct{}{}
+
+			if f.Retryable() && attempt < maxAttempts {
+				retry = append(retry, f.Action)
+
+				continue
+			}
+
+			metrics.SearchIndexedInc(f.Action.Index, string(f.Action.Op), metrics.Failed)
+			i.log.Error("search bulk item",
+				field.String("index", f.Action.Index), field.String("doc_id", f.Action.ID),
+				field.Int("status", f.Status), field.String("type", f.Type), field.String("reason", f.Reason))
+		}
+
+		for n, a := range pending {
+			if _, ok := failedItems[n]; !ok {
+				metrics.SearchIndexedInc(a.Index, string(a.Op), metrics.Success)
+			}
+		}
+
+		if pending = retry; len(pending) > 0 && !sleep(ctx, retryBackoff*time.Duration(attempt)) {
+			count(pending, metrics.Failed)
+
+			return
+		}
+	}
+}
+
+func count(actions []Action, status string) {
+	for _, a := range actions {
+		metrics.SearchIndexedInc(a.Index, string(a.Op), status)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
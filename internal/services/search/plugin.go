@@ -0,0 +1,76 @@
+package search
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+const pluginName = "search_indexer"
+
+// Plugin - gorm plugin which keeps index in sync with DB: created, updated and deleted models implementing Indexable
+// are enqueued to Indexer. Only models which gorm knows are synced, i.e. `Model(&row)` with filled primary key,
+// batch updates by conditions (`Model(&T{}).Where(...)`) are skipped. NB! Callbacks run before commit of
+// transaction, so document of rolled back row may be indexed (it's fixed by next write of row).
+type Plugin struct {
+	indexer *Indexer
+}
+
+// NewPlugin - constructor of Plugin, register it by db.Use.
+func NewPlugin(indexer *Indexer) *Plugin {
+	return &Plugin{indexer: indexer}
+}
+
+// Name - implements gorm.Plugin.
+func (p *Plugin) Name() string {
+	return pluginName
+}
+
+// Initialize - implements gorm.Plugin.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register(pluginName+":create", p.callback(OpIndex)); err != nil {
+		return err
+	}
+
+	if err := db.Callback().Update().After("gorm:update").Register(pluginName+":update", p.callback(OpIndex)); err != nil {
+		return err
+	}
+
+	return db.Callback().Delete().After("gorm:delete").Register(pluginName+":delete", p.callback(OpDelete))
+}
+
+func (p *Plugin) callback(op Op) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.RowsAffected == 0 || db.Statement.Schema == nil {
+			return
+		}
+
+		rv := reflect.Indirect(db.Statement.ReflectValue)
+
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				p.enqueue(op, rv.Index(i))
+			}
+		case reflect.Struct:
+			p.enqueue(op, rv)
+		default:
+		}
+	}
+}
+
+func (p *Plugin) enqueue(op Op, v reflect.Value) {
+	v = reflect.Indirect(v)
+	if !v.IsValid() || !v.CanAddr() {
+		return
+	}
+
+	m, ok := v.Addr().Interface().(Indexable)
+	if !ok {
+		return
+	}
+
+	if doc := m.SearchDocument(); doc.ID != "" {
+		p.indexer.Enqueue(Action{Op: op, Document: doc})
+	}
+}
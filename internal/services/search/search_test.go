@@ -0,0 +1,216 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/sorting"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+)
+
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{Addresses: []string{srv.URL + "/"}, IndexPrefix: "test-"})
+	require.NoError(t, err)
+
+	return client
+}
+
+func readNDJSON(t *testing.T, r *http.Request) []map[string]any {
+	var lines []map[string]any
+
+	sc := bufio.NewScanner(r.Body)
+	for sc.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &line))
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestClient_Bulk(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, ndjsonContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, []map[string]any{
+			{"index": map[string]any{"_index": "test-keys", "_id": "1", "routing": "t1"}},
+			{"name": "first"},
+			{"delete": map[string]any{"_index": "test-keys", "_id": "2"}},
+			{"delete": map[string]any{"_index": "test-keys", "_id": "3"}},
+			{"index": map[string]any{"_index": "test-keys", "_id": "4"}},
+			{"name": "fourth"},
+		}, readNDJSON(t, r))
+
+		_, _ = w.Write([]byte(`{"errors": true, "items": [
+			{"index": {"status": 201}},
+			{"delete": {"status": 404, "error": {"type": "not_found", "reason": "absent"}}},
+			{"delete": {"status": 200}},
+			{"index": {"status": 429, "error": {"type": "es_rejected_execution_exception", "reason": "busy"}}}
+		]}`))
+	})
+
+	actions := []Action{
+		{Op: OpIndex, Document: Document{Index: "keys", ID: "1", Routing: "t1", Body: map[string]any{"name": "first"}}},
+		{Op: OpDelete, Document: Document{Index: "keys", ID: "2"}},
+		{Op: OpDelete, Document: Document{Index: "keys", ID: "3"}},
+		{Op: OpIndex, Document: Document{Index: "keys", ID: "4", Body: map[string]any{"name": "fourth"}}},
+	}
+
+	failed, err := client.Bulk(context.Background(), actions)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 3, failed[0].Item)
+	assert.Equal(t, "4", failed[0].Action.ID)
+	assert.True(t, failed[0].Retryable())
+}
+
+func TestClient_Search(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test-keys/_search", r.URL.Path)
+		assert.Equal(t, "t1", r.URL.Query().Get("routing"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `{
+			"from": 20, "size": 10, "track_total_hits": true,
+			"query": {"bool": {
+				"filter": [
+					{"term": {"tenant_id": "t1"}},
+					{"range": {"created_at": {"gte": "2024-01-01T00:00:00Z", "lt": "2024-02-01T00:00:00Z"}}}
+				],
+				"must": {"simple_query_string": {"query": "prod key", "default_operator": "and"}}
+			}},
+			"sort": [{"_score": {"order": "desc"}}, {"id": {"order": "desc"}}]
+		}`, mustJSON(t, body))
+
+		_, _ = w.Write([]byte(`{"hits": {"total": {"value": 21}, "hits": [
+			{"_index": "test-keys", "_id": "7", "_score": 1.5, "_source": {"name": "prod key"}}
+		]}}`))
+	})
+
+	res, err := client.Search(context.Background(), Query{
+		Index:    "keys",
+		TenantID: "t1",
+		Text:     "prod key",
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Sort: sorting.Sort{
+			{Field: "score", Column: "_score", Desc: true},
+			{Field: "id", Column: "id", Desc: true},
+		},
+		Offset: 20,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 21, res.Total)
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, "keys", res.Hits[0].Index)
+	assert.Equal(t, "7", res.Hits[0].ID)
+	assert.InDelta(t, 1.5, *res.Hits[0].Score, 0.001)
+	assert.JSONEq(t, `{"name": "prod key"}`, string(res.Hits[0].Source))
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"type": "resource_already_exists_exception", "reason": "exists"}}`))
+	})
+
+	require.NoError(t, client.EnsureIndex(context.Background(), "keys", map[string]any{}))
+
+	client = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"type": "index_not_found_exception", "reason": "no such index"}}`))
+	})
+
+	_, err := client.Search(context.Background(), Query{Index: "keys"})
+
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusNotFound, respErr.Status)
+	assert.Equal(t, "index_not_found_exception", respErr.Type)
+	assert.False(t, respErr.Retryable())
+
+	_, err = NewClient(Config{})
+	assert.ErrorIs(t, err, ErrNoAddresses)
+}
+
+func TestIndexer(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]map[string]any
+		calls    int
+	)
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls == 1 { // temporary error of whole request is retried.
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		requests = append(requests, readNDJSON(t, r))
+		_, _ = w.Write([]byte(`{"errors": false, "items": []}`))
+	})
+
+	indexer := NewIndexer(client, IndexerConfig{BulkSize: 2, FlushInterval: time.Hour}, logger.NewNop())
+
+	for _, id := range []string{"1", "2", "3"} {
+		indexer.Enqueue(Action{Op: OpDelete, Document: Document{Index: "keys", ID: id}})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- indexer.Run(ctx) }()
+
+	// first batch is full, the rest is flushed on stop.
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(requests) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, requests, 2)
+	assert.Len(t, requests[0], 2)
+	assert.Equal(t, []map[string]any{{"delete": map[string]any{"_index": "test-keys", "_id": "3"}}}, requests[1])
+}
+
+func TestIndexer_EnqueueDropsWhenFull(t *testing.T) {
+	indexer := NewIndexer(nil, IndexerConfig{QueueSize: 1}, logger.NewNop())
+
+	indexer.Enqueue(Action{Op: OpDelete, Document: Document{Index: "keys", ID: "1"}})
+	indexer.Enqueue(Action{Op: OpDelete, Document: Document{Index: "keys", ID: "2"}})
+
+	assert.Len(t, indexer.queue, 1)
+}
+
+func mustJSON(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return string(data)
+}
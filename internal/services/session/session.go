@@ -0,0 +1,231 @@
+// Package session - split-token based user sessions (`sessions` table).
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/logger"
+	"github.com/imperiuse/go-app-skeleton/internal/logger/field"
+)
+
+const (
+	defaultTTL           = 24 * time.Hour
+	defaultPurgeInterval = 10 * time.Minute
+)
+
+// dummyHash - hash compared with password of unknown email, so response time doesn't reveal registered emails.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrSessionNotFound    = errors.New("session not found or expired")
+)
+
+type (
+	// Config - session service config.
+	Config struct {
+		TTL           time.Duration // lifetime of session, also sliding window size.
+		PurgeInterval time.Duration // how often expired sessions are deleted.
+	}
+
+	// Service - session service.
+	Service struct {
+		cfg Config
+		db  *database.DB
+		log *logger.Logger
+
+		now     func() time.Time
+		compare func(hash []byte, password []byte) error
+	}
+)
+
+// New - constructor of session Service.
+func New(cfg Config, db *database.DB, log *logger.Logger) *Service {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = defaultPurgeInterval
+	}
+
+	return &Service{cfg: cfg, db: db, log: log, now: time.Now, compare: bcrypt.CompareHashAndPassword}
+}
+
+// TTL - lifetime of session.
+func (s *Service) TTL() time.Duration {
+	return s.cfg.TTL
+}
+
+// Login - check user credentials and issue new session.
+func (s *Service) Login(ctx context.Context, email string, password string, ip string, userAgent string) (
+	token string,
+	session *tables.Session,
+	err error,
+) {
+	var user tables.User
+	if err = s.db.WithContext(ctx).Where("email = ?", email).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = s.compare(dummyHash, []byte(password)) // same work as for known email.
+
+			return "", nil, ErrInvalidCredentials
+		}
+
+		return "", nil, fmt.Errorf("find user: %w", err)
+	}
+
+	if s.compare([]byte(user.Password), []byte(password)) != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	return s.Create(ctx, user.ID, ip, userAgent)
+}
+
+// Create - issue new session for user, returns token which must be given to client (only once).
+func (s *Service) Create(ctx context.Context, userID int, ip string, userAgent string) (
+	string,
+	*tables.Session,
+	error,
+) {
+	t, err := newSplitToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := s.now().UTC()
+	session := &tables.Session{
+		CreatedAt:  now,
+		ExpiredAt:  now.Add(s.cfg.TTL),
+		UserID:     userID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		Identifier: t.identifier,
+		HVerifier:  t.hashedVerifier(),
+	}
+
+	if err = s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return "", nil, fmt.Errorf("create session: %w", err)
+	}
+
+	return t.String(), session, nil
+}
+
+// Validate - check token, and prolong session (sliding expiration) if half of TTL already passed.
+// Returns renewed=true if expired_at was moved, so caller should refresh the cookie.
+func (s *Service) Validate(ctx context.Context, token string) (session *tables.Session, renewed bool, err error) {
+	t, err := parseSplitToken(token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := s.now().UTC()
+
+	session = &tables.Session{}
+	if err = s.db.WithContext(ctx).
+		Where("identifier = ? AND expired_at > ?", t.identifier, now).
+		Take(session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrSessionNotFound
+		}
+
+		return nil, false, fmt.Errorf("find session: %w", err)
+	}
+
+	if !t.verify(session.HVerifier) {
+		return nil, false, ErrSessionNotFound
+	}
+
+	if session.ExpiredAt.Sub(now) > s.cfg.TTL/2 {
+		return session, false, nil
+	}
+
+	session.ExpiredAt = now.Add(s.cfg.TTL)
+	if err = s.db.WithContext(ctx).Model(session).Update("expired_at", session.ExpiredAt).Error; err != nil {
+		return nil, false, fmt.Errorf("prolong session: %w", err)
+	}
+
+	return session, true, nil
+}
+
+// ListActive - list active sessions of user (newest first).
+func (s *Service) ListActive(ctx context.Context, userID int) ([]tables.Session, error) {
+	var sessions []tables.Session
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND expired_at > ?", userID, s.now().UTC()).
+		Order("created_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Revoke - revoke one session of user.
+func (s *Service) Revoke(ctx context.Context, userID int, sessionID int) error {
+	res := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", sessionID, userID).Delete(&tables.Session{})
+	if res.Error != nil {
+		return fmt.Errorf("revoke session: %w", res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeOthers - revoke all sessions of user, except given one (e.g. "logout from all other devices").
+// If ip is not empty - only sessions from that ip are revoked.
+func (s *Service) RevokeOthers(ctx context.Context, userID int, exceptSessionID int, ip string) (int64, error) {
+	q := s.db.WithContext(ctx).Where("user_id = ? AND id <> ?", userID, exceptSessionID)
+	if ip != "" {
+		q = q.Where("ip = ?", ip)
+	}
+
+	res := q.Delete(&tables.Session{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// PurgeExpired - delete all expired sessions.
+func (s *Service) PurgeExpired(ctx context.Context) (int64, error) {
+	res := s.db.WithContext(ctx).Where("expired_at <= ?", s.now().UTC()).Delete(&tables.Session{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("purge expired sessions: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// RunPurger - periodically purge expired sessions until ctx is done.
+func (s *Service) RunPurger(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n, err := s.PurgeExpired(ctx)
+			if err != nil {
+				s.log.Error("session purger", field.Error(err))
+
+				continue
+			}
+
+			s.log.Debug("session purger", field.Int64("purged", n))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/dbtest"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+)
+
+// newTestService - service over dry run db, which finds users by email in given map.
+func newTestService(t *testing.T, users map[string]tables.User) *Service {
+	db := dbtest.DryRun(t)
+
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:users", func(tx *gorm.DB) {
+		dest, ok := tx.Statement.Dest.(*tables.User)
+		if !ok || len(tx.Statement.Vars) == 0 {
+			return
+		}
+
+		user, ok := users[tx.Statement.Vars[0].(string)] //nolint:forcetypeassert // email is string.
+		if !ok {
+			_ = tx.AddError(gorm.ErrRecordNotFound)
+
+			return
+		}
+
+		*dest = user
+	}))
+
+	return New(Config{}, &database.DB{DB: db}, nil)
+}
+
+func TestService_Login(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	s := newTestService(t, map[string]tables.User{"user@example.com": {ID: 7, Password: string(hash)}})
+
+	var compared [][]byte
+
+	s.compare = func(hash []byte, password []byte) error {
+		compared = append(compared, hash)
+
+		return bcrypt.CompareHashAndPassword(hash, password)
+	}
+
+	ctx := context.Background()
+
+	token, session, err := s.Login(ctx, "user@example.com", "secret", "127.0.0.1", "test")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, 7, session.UserID)
+	assert.Equal(t, "127.0.0.1", session.IP)
+
+	_, _, err = s.Login(ctx, "user@example.com", "wrong", "127.0.0.1", "test")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, _, err = s.Login(ctx, "unknown@example.com", "secret", "127.0.0.1", "test")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	require.Len(t, compared, 3, "password is compared for unknown email too")
+	assert.Equal(t, dummyHash, compared[2])
+}
+
+func TestDummyHash(t *testing.T) {
+	cost, err := bcrypt.Cost(dummyHash)
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
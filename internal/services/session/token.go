@@ -0,0 +1,76 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	identifierBytes = 16 // hex -> 32 symbols, fits `sessions.identifier CHAR(32)`.
+	verifierBytes   = 32
+
+	tokenSeparator = "."
+)
+
+var ErrMalformedToken = errors.New("malformed session token")
+
+// splitToken - pair of public identifier (stored as is) and secret verifier (only sha256 hash is stored).
+type splitToken struct {
+	identifier string
+	verifier   string
+}
+
+// newSplitToken - generate new random split token.
+func newSplitToken() (splitToken, error) {
+	id := make([]byte, identifierBytes)
+	if _, err := rand.Read(id); err != nil {
+		return splitToken{}, fmt.Errorf("rand.Read identifier: %w", err)
+	}
+
+	verifier := make([]byte, verifierBytes)
+	if _, err := rand.Read(verifier); err != nil {
+		return splitToken{}, fmt.Errorf("rand.Read verifier: %w", err)
+	}
+
+	return splitToken{
+		identifier: hex.EncodeToString(id),
+		verifier:   base64.RawURLEncoding.EncodeToString(verifier),
+	}, nil
+}
+
+// parseSplitToken - parse token value from cookie.
+func parseSplitToken(token string) (splitToken, error) {
+	identifier, verifier, found := strings.Cut(token, tokenSeparator)
+	if !found || len(identifier) != identifierBytes*2 || verifier == "" {
+		return splitToken{}, ErrMalformedToken
+	}
+
+	if _, err := hex.DecodeString(identifier); err != nil {
+		return splitToken{}, ErrMalformedToken
+	}
+
+	return splitToken{identifier: identifier, verifier: verifier}, nil
+}
+
+// String - token value for client side (cookie).
+func (t splitToken) String() string {
+	return t.identifier + tokenSeparator + t.verifier
+}
+
+// hashedVerifier - hex sha256 of verifier, fits `sessions.h_verifier CHAR(64)`.
+func (t splitToken) hashedVerifier() string {
+	h := sha256.Sum256([]byte(t.verifier))
+
+	return hex.EncodeToString(h[:])
+}
+
+// verify - constant time compare of verifier with stored hash.
+func (t splitToken) verify(hVerifier string) bool {
+	return subtle.ConstantTimeCompare([]byte(t.hashedVerifier()), []byte(strings.TrimSpace(hVerifier))) == 1
+}
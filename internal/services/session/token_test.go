@@ -0,0 +1,42 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitToken(t *testing.T) {
+	tok, err := newSplitToken()
+	require.NoError(t, err)
+
+	assert.Len(t, tok.identifier, 32)
+	assert.Len(t, tok.hashedVerifier(), 64)
+
+	parsed, err := parseSplitToken(tok.String())
+	require.NoError(t, err)
+	assert.Equal(t, tok, parsed)
+
+	assert.True(t, parsed.verify(tok.hashedVerifier()))
+	assert.True(t, parsed.verify(tok.hashedVerifier()+"  ")) // CHAR(64) could be padded by db.
+
+	other, err := newSplitToken()
+	require.NoError(t, err)
+	assert.False(t, parsed.verify(other.hashedVerifier()))
+	assert.NotEqual(t, tok.identifier, other.identifier)
+}
+
+func TestParseSplitToken_Malformed(t *testing.T) {
+	for _, token := range []string{
+		"",
+		"abc",
+		"abc.def",
+		"0123456789abcdef0123456789abcdef",
+		"0123456789abcdef0123456789abcdef.",
+		"0123456789abcdef0123456789abcdeZ.verifier",
+	} {
+		_, err := parseSplitToken(token)
+		assert.ErrorIs(t, err, ErrMalformedToken, token)
+	}
+}
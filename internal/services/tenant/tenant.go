@@ -0,0 +1,323 @@
+// Package tenant - tenants management: status (active, suspended, deleted) and per-tenant settings.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database"
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+	"github.com/imperiuse/go-app-skeleton/internal/services/cache"
+)
+
+const (
+	defaultCacheTTL = time.Minute
+	maxSettingsKeys = 64
+
+	// maxCachedMisses - not registered tenants aren't cached if cache is bigger: tenant ids of requests are set by
+	// clients, so cache of misses isn't bounded by number of tenants.
+	maxCachedMisses = 10_000
+)
+
+var (
+	ErrTenantNotFound  = errors.New("tenant not found")
+	ErrInvalidSettings = errors.New("invalid tenant settings")
+	ErrVersionMismatch = errors.New("tenant is modified by other request")
+)
+
+var settingsKeyRegexp = regexp.MustCompile(`^[a-z0-9_.-]{1,64}$`)
+
+type (
+	// Config - tenant service config.
+	Config struct {
+		CacheTTL time.Duration // how long tenants are cached, changes of other replicas are visible after it.
+	}
+
+	// ResponseInvalidator - cache of responses, @see services/cache.Store.
+	ResponseInvalidator interface {
+		InvalidateTags(tags ...string)
+	}
+
+	// Service - tenant service.
+	Service struct {
+		cfg       Config
+		db        *database.DB
+		responses ResponseInvalidator // optional.
+
+		mu       sync.RWMutex
+		cache    map[uuid.UUID]cachedTenant
+		prunedAt time.Time // last time expired entries were evicted.
+
+		now func() time.Time
+	}
+
+	cachedTenant struct {
+		tenant    *tables.Tenant // nil - tenant isn't registered.
+		expiredAt time.Time
+	}
+)
+
+// New - constructor of tenant Service. Cached responses of tenant are invalidated by its changes, responses can be nil.
+func New(cfg Config, db *database.DB, responses ResponseInvalidator) *Service {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+
+	return &Service{
+		cfg:       cfg,
+		db:        db,
+		responses: responses,
+		cache:     make(map[uuid.UUID]cachedTenant),
+		now:       time.Now,
+	}
+}
+
+// Create - register new active tenant.
+func (s *Service) Create(ctx context.Context, name string, settings tables.TenantSettings) (*tables.Tenant, error) {
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	t := &tables.Tenant{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+		Name:      name,
+		Status:    tables.TenantActive,
+		Settings:  settings,
+	}
+
+	if err := s.db.WithContext(ctx).Create(t).Error; err != nil {
+		return nil, fmt.Errorf("create tenant: %w", err)
+	}
+
+	s.Invalidate(t.ID)
+
+	return t, nil
+}
+
+// List - list all tenants (including suspended and deleted).
+func (s *Service) List(ctx context.Context) ([]tables.Tenant, error) {
+	var tenants []tables.Tenant
+	if err := s.db.WithContext(ctx).Order("created_at, id").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// Get - tenant by id (not cached).
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*tables.Tenant, error) {
+	t := &tables.Tenant{}
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Take(t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+
+	return t, nil
+}
+
+// Tenant - cached tenant, nil if tenant isn't registered (tenants of tokens issued before `tenants` table).
+func (s *Service) Tenant(ctx context.Context, id uuid.UUID) (*tables.Tenant, error) {
+	if t, ok := s.fromCache(id); ok {
+		return t, nil
+	}
+
+	t, err := s.Get(ctx, id)
+	if errors.Is(err, ErrTenantNotFound) {
+		t, err = nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.store(id, t)
+
+	return t, nil
+}
+
+// Settings - cached settings of tenant, zero settings (service defaults) if tenant isn't registered.
+func (s *Service) Settings(ctx context.Context, id uuid.UUID) (tables.TenantSettings, error) {
+	t, err := s.Tenant(ctx, id)
+	if err != nil || t == nil {
+		return tables.TenantSettings{}, err
+	}
+
+	return t.Settings, nil
+}
+
+// UpdateSettings - replace settings of not deleted tenant. Version is expected version of tenant (optimistic
+// concurrency, ErrVersionMismatch if tenant is modified), 0 - any.
+func (s *Service) UpdateSettings(
+	ctx context.Context,
+	id uuid.UUID,
+	settings tables.TenantSettings,
+	version int64,
+) (*tables.Tenant, error) {
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, id, version, "update tenant settings", map[string]any{"settings": settings})
+}
+
+// Suspend - suspend tenant, all its requests are rejected until Resume.
+func (s *Service) Suspend(ctx context.Context, id uuid.UUID) (*tables.Tenant, error) {
+	return s.update(ctx, id, 0, "suspend tenant", map[string]any{
+		"status":       tables.TenantSuspended,
+		"suspended_at": s.now().UTC(),
+	})
+}
+
+// Resume - make suspended tenant active.
+func (s *Service) Resume(ctx context.Context, id uuid.UUID) (*tables.Tenant, error) {
+	return s.update(ctx, id, 0, "resume tenant", map[string]any{
+		"status":       tables.TenantActive,
+		"suspended_at": nil,
+	})
+}
+
+// Delete - soft delete tenant, it can't be resumed. Data of tenant is kept. Version - @see UpdateSettings.
+func (s *Service) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	_, err := s.update(ctx, id, version, "delete tenant", map[string]any{
+		"status":     tables.TenantDeleted,
+		"deleted_at": s.now().UTC(),
+	})
+
+	return err
+}
+
+// Invalidate - drop cached tenant and its cached responses.
+func (s *Service) Invalidate(id uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, id)
+	s.mu.Unlock()
+
+	if s.responses != nil {
+		s.responses.InvalidateTags(cache.TenantTag(id))
+	}
+}
+
+// update - update not deleted tenant of version (0 - any), increment its version and drop it from cache.
+func (s *Service) update(
+	ctx context.Context,
+	id uuid.UUID,
+	version int64,
+	op string,
+	values map[string]any,
+) (*tables.Tenant, error) {
+	values["updated_at"] = s.now().UTC()
+	values["version"] = gorm.Expr("version + 1")
+
+	t := &tables.Tenant{}
+
+	q := s.db.WithContext(ctx).
+		Model(t).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status <> ?", id, tables.TenantDeleted)
+	if version > 0 {
+		q = q.Where("version = ?", version)
+	}
+
+	res := q.Updates(values)
+	if res.Error != nil {
+		return nil, fmt.Errorf("%s: %w", op, res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		if version > 0 {
+			if current, err := s.Get(ctx, id); err == nil && current.Status != tables.TenantDeleted {
+				return nil, ErrVersionMismatch
+			}
+		}
+
+		return nil, ErrTenantNotFound
+	}
+
+	s.Invalidate(id)
+
+	return t, nil
+}
+
+// store - cache tenant, nil - not registered tenant. Expired entries are evicted once per CacheTTL.
+func (s *Service) store(id uuid.UUID, t *tables.Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.prunedAt) >= s.cfg.CacheTTL {
+		for id, c := range s.cache {
+			if now.After(c.expiredAt) {
+				delete(s.cache, id)
+			}
+		}
+
+		s.prunedAt = now
+	}
+
+	if t == nil && len(s.cache) >= maxCachedMisses {
+		return
+	}
+
+	s.cache[id] = cachedTenant{tenant: t, expiredAt: now.Add(s.cfg.CacheTTL)}
+}
+
+func (s *Service) fromCache(id uuid.UUID) (*tables.Tenant, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.cache[id]
+	if !ok || s.now().After(c.expiredAt) {
+		return nil, false
+	}
+
+	return c.tenant, true
+}
+
+// ValidateSettings - check time zone, locale and names of features and limits.
+func ValidateSettings(s tables.TenantSettings) error {
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil || s.TimeZone == "Local" {
+			return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSettings, s.TimeZone)
+		}
+	}
+
+	if s.Locale != "" {
+		if _, err := language.Parse(s.Locale); err != nil {
+			return fmt.Errorf("%w: invalid locale %q", ErrInvalidSettings, s.Locale)
+		}
+	}
+
+	if len(s.Features) > maxSettingsKeys || len(s.Limits) > maxSettingsKeys {
+		return fmt.Errorf("%w: too many features or limits, max %d", ErrInvalidSettings, maxSettingsKeys)
+	}
+
+	for name := range s.Features {
+		if !settingsKeyRegexp.MatchString(name) {
+			return fmt.Errorf("%w: invalid feature name %q", ErrInvalidSettings, name)
+		}
+	}
+
+	for name, v := range s.Limits {
+		if !settingsKeyRegexp.MatchString(name) || v < 0 {
+			return fmt.Errorf("%w: invalid limit %q", ErrInvalidSettings, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imperiuse/go-app-skeleton/internal/database/tables"
+)
+
+func TestValidateSettings(t *testing.T) {
+	for _, tc := range []struct {
+		settings tables.TenantSettings
+		valid    bool
+	}{
+		{tables.TenantSettings{}, true},
+		{tables.TenantSettings{
+			TimeZone: "Europe/Berlin",
+			Locale:   "de-DE",
+			Features: map[string]bool{"reports.export": true},
+			Limits:   map[string]int64{"api_keys": 10},
+		}, true},
+		{tables.TenantSettings{TimeZone: "Mars/Olympus"}, false},
+		{tables.TenantSettings{TimeZone: "Local"}, false},
+		{tables.TenantSettings{Locale: "not a locale"}, false},
+		{tables.TenantSettings{Features: map[string]bool{"Bad Name": true}}, false},
+		{tables.TenantSettings{Limits: map[string]int64{"api_keys": -1}}, false},
+	} {
+		err := ValidateSettings(tc.settings)
+		if tc.valid {
+			assert.NoError(t, err, tc.settings)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidSettings, tc.settings)
+		}
+	}
+}
+
+func TestSettingsJSONB(t *testing.T) {
+	settings := tables.TenantSettings{TimeZone: "UTC", Features: map[string]bool{"beta": true}}
+
+	v, err := settings.Value()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"time_zone": "UTC", "features": {"beta": true}}`, string(v.([]byte)))
+
+	var scanned tables.TenantSettings
+	require.NoError(t, scanned.Scan(v))
+	assert.Equal(t, settings, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, tables.TenantSettings{}, scanned)
+}
+
+func TestService_Store(t *testing.T) {
+	s := New(Config{}, nil, nil)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for range maxCachedMisses + 1 {
+		s.store(uuid.New(), nil)
+	}
+
+	assert.Len(t, s.cache, maxCachedMisses, "misses aren't cached if cache is full")
+
+	registered := uuid.New()
+	s.store(registered, &tables.Tenant{ID: registered})
+	assert.Len(t, s.cache, maxCachedMisses+1, "registered tenants are cached")
+
+	now = now.Add(2 * defaultCacheTTL)
+
+	s.store(uuid.New(), nil)
+	assert.Len(t, s.cache, 1, "expired entries are evicted")
+}
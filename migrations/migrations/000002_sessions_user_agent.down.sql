@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx__sessions__expired_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS user_agent;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT ''; -- device of session
+CREATE INDEX IF NOT EXISTS idx__sessions__expired_at ON sessions (expired_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx__users_roles__user_id__role_id;
+DROP INDEX IF EXISTS idx__roles__name;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx__roles__name ON roles (name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx__users_roles__user_id__role_id ON users_roles (user_id, role_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx__api_keys__tenant_id;
+DROP TABLE IF EXISTS api_keys;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           INTEGER     PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    created_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+
+    tenant_id    UUID        NOT NULL,              -- owner of key
+    created_by   INTEGER     NOT NULL DEFAULT 0,    -- user id (0 - created by other api key)
+    name         TEXT        NOT NULL DEFAULT '',
+
+    prefix       CHAR(12)    NOT NULL UNIQUE,       -- visible part of key, this field with idx
+    h_secret     CHAR(64)    NOT NULL,              -- sha256 hash of secret part of key
+    rights       SMALLINT    NOT NULL CHECK (rights > 0), -- scopes, same bitmask as roles.rights
+
+    expired_at   TIMESTAMP   NULL,
+    last_used_at TIMESTAMP   NULL,
+    revoked_at   TIMESTAMP   NULL
+);
+
+CREATE INDEX idx__api_keys__tenant_id ON api_keys (tenant_id);
+
+COMMENT ON TABLE api_keys IS 'Таблица API ключей (API keys) - ключи доступа сервисов (machine clients)';
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- token buckets of rate limiter shared between replicas, see internal/services/ratelimit/postgres.go
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets
+(
+    key          TEXT              PRIMARY KEY,
+    tokens       DOUBLE PRECISION  NOT NULL,
+    allowed      BOOLEAN           NOT NULL DEFAULT TRUE,  -- result of last take
+    updated_at   TIMESTAMPTZ       NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE rate_limit_buckets IS 'Таблица ограничения частоты запросов (Rate limit) - token buckets';
+
+COMMIT;
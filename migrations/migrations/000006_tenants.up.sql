@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS tenants
+(
+    id           UUID        PRIMARY KEY,
+    created_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+
+    name         TEXT        NOT NULL,
+    status       TEXT        NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended', 'deleted')),
+    settings     JSONB       NOT NULL DEFAULT '{}',   -- time zone, locale, feature toggles, limits
+
+    suspended_at TIMESTAMP   NULL,
+    deleted_at   TIMESTAMP   NULL                     -- soft delete, status = 'deleted'
+);
+
+COMMENT ON TABLE tenants IS 'Таблица арендаторов (tenants) - статус и настройки арендатора';
+
+COMMIT;
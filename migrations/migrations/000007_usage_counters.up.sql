@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS usage_counters
+(
+    tenant_id  UUID      NOT NULL,
+    metric     TEXT      NOT NULL,                                    -- requests, reports, storage_bytes
+    period     TEXT      NOT NULL CHECK (period IN ('hour', 'day')),
+    bucket     TIMESTAMP NOT NULL,                                    -- start of period (UTC)
+    value      BIGINT    NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, metric, period, bucket)
+);
+
+COMMENT ON TABLE usage_counters IS 'Таблица потребления (usage) арендаторов по метрикам, агрегаты по часам и дням';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx__idempotency_keys__expired_at;
+DROP TABLE IF EXISTS idempotency_keys;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- requests with `Idempotency-Key` header and their responses, see internal/services/idempotency
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    scope        TEXT         NOT NULL,                  -- tenant and client (user or api key)
+    key          TEXT         NOT NULL,
+    fingerprint  TEXT         NOT NULL,                  -- sha256 of method, path, query and body
+    status_code  INT          NOT NULL DEFAULT 0,        -- 0 - request is in flight
+    headers      JSONB,
+    body         BYTEA,
+    locked_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expired_at   TIMESTAMPTZ  NOT NULL,
+
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx__idempotency_keys__expired_at ON idempotency_keys (expired_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Таблица ключей идемпотентности запросов (Idempotency-Key) и сохранённых ответов';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- version of tenant is incremented by each change, it's ETag of tenant (optimistic concurrency by If-Match)
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMIT;
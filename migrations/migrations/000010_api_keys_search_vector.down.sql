@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx__api_keys__search_vector;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- full-text search by name and prefix, @see tables.APIKeySearch (statements are fulltext.Index.DDL)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple'::regconfig, COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple'::regconfig, COALESCE(prefix, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx__api_keys__search_vector ON api_keys USING GIN (search_vector);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE usage_counters NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE usage_counters DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON usage_counters;
+
+ALTER TABLE api_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON api_keys;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- row level security of tenant scoped tables, @see tenancy.RLSStatements and `tenancy.rls` config
+-- (tenancy plugin sets `app.tenant_id` and `app.bypass_rls` of each statement)
+DROP POLICY IF EXISTS tenant_isolation ON api_keys;
+CREATE POLICY tenant_isolation ON api_keys USING (
+    current_setting('app.bypass_rls', true) = 'on'
+    OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid
+);
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON usage_counters;
+CREATE POLICY tenant_isolation ON usage_counters USING (
+    current_setting('app.bypass_rls', true) = 'on'
+    OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid
+);
+ALTER TABLE usage_counters ENABLE ROW LEVEL SECURITY;
+ALTER TABLE usage_counters FORCE ROW LEVEL SECURITY;
+
+COMMIT;
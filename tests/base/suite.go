@@ -91,6 +91,7 @@ func (s *SuiteForUnit) Setup() {
 	},
 		s.Engine,
 		zap.NewNop(),
+		api.Deps{},
 	)
 }
 